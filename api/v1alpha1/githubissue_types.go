@@ -32,21 +32,267 @@ type GithubIssueSpec struct {
 	Repository  string `json:"repository,omitempty"`
 	Title       string `json:"title,omitempty"`
 	Description string `json:"description,omitempty"`
+
+	// IssueNumber binds the resource to an existing issue in the repository
+	// instead of opening a new one.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	IssueNumber int `json:"issueNumber,omitempty"`
+
+	// CredentialsRef names a Secret in the namespace of the resource holding
+	// the GitHub token to file the issue with. When unset, the namespace's
+	// default credentials Secret is used if the operator is configured with
+	// one, and the operator's own credentials otherwise. Namespaces lacking
+	// the default Secret only fall back to the operator's credentials when
+	// the operator allows it.
+	// +optional
+	CredentialsRef *CredentialsReference `json:"credentialsRef,omitempty"`
+
+	// State is the state the issue should be in. When unset, the operator
+	// leaves the state of the issue alone.
+	// +optional
+	// +kubebuilder:validation:Enum=open;closed
+	State string `json:"state,omitempty"`
+
+	// StateReason is why the issue is closed. It defaults to completed and is
+	// ignored while the issue is open.
+	// +optional
+	// +kubebuilder:validation:Enum=completed;not_planned;duplicate
+	StateReason string `json:"stateReason,omitempty"`
+
+	// StatePolicy decides what happens when someone opens or closes the
+	// issue on GitHub. Enforce restores spec.state; Respect keeps their
+	// change until the spec is edited again.
+	// +optional
+	// +kubebuilder:default=Enforce
+	StatePolicy StatePolicy `json:"statePolicy,omitempty"`
+
+	// DeletionPolicy decides what happens to the issue when the resource is
+	// deleted. Close closes the issue and comments on it, Lock locks its
+	// conversation and Orphan leaves it as it is.
+	// +optional
+	// +kubebuilder:default=Close
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty"`
+
+	// Labels are applied to the issue. Labels missing from the repository
+	// are created. Only labels the operator added are removed again when
+	// they are dropped from this list.
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	Labels []Label `json:"labels,omitempty"`
+
+	// Assignees are the GitHub logins to assign the issue to. When the
+	// operator is configured with an identity map, Kubernetes user names
+	// are translated to GitHub logins. Users who cannot be assigned issues
+	// in the repository are reported in the AssigneesValid condition. Only
+	// assignees the operator added are unassigned again when they are
+	// dropped from this list.
+	// +optional
+	// +listType=set
+	// +kubebuilder:validation:MaxItems=10
+	Assignees []string `json:"assignees,omitempty"`
+
+	// Milestone adds the issue to the milestone with the given title. When
+	// unset, the operator leaves the milestone of the issue alone.
+	// +optional
+	Milestone *Milestone `json:"milestone,omitempty"`
+}
+
+// Milestone selects a milestone of the repository by its title.
+type Milestone struct {
+	// Title of the milestone.
+	// +kubebuilder:validation:MinLength=1
+	Title string `json:"title"`
+
+	// Create creates the milestone if the repository has none with this
+	// title. Otherwise a missing milestone is reported on the status.
+	// +optional
+	Create bool `json:"create,omitempty"`
+
+	// DueOn is the due date of a milestone created by the operator.
+	// +optional
+	DueOn *metav1.Time `json:"dueOn,omitempty"`
 }
 
+// Label is a label to apply to an issue.
+type Label struct {
+	// Name of the label.
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=50
+	Name string `json:"name"`
+
+	// Color of the label as a hex code without the leading #, used when
+	// the label has to be created.
+	// +optional
+	// +kubebuilder:validation:Pattern=`^[0-9a-fA-F]{6}$`
+	Color string `json:"color,omitempty"`
+
+	// Description of the label, used when the label has to be created.
+	// +optional
+	// +kubebuilder:validation:MaxLength=100
+	Description string `json:"description,omitempty"`
+}
+
+// StatePolicy decides whether changes to the state of an issue made on GitHub
+// are reverted.
+// +kubebuilder:validation:Enum=Enforce;Respect
+type StatePolicy string
+
+const (
+	// StatePolicyEnforce keeps the issue in spec.state.
+	StatePolicyEnforce StatePolicy = "Enforce"
+	// StatePolicyRespect only applies spec.state when the spec changes.
+	StatePolicyRespect StatePolicy = "Respect"
+)
+
+// CredentialsReference selects a GitHub token stored in a Secret.
+type CredentialsReference struct {
+	// Name of the Secret.
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Key of the token in the Secret.
+	// +optional
+	// +kubebuilder:default=token
+	Key string `json:"key,omitempty"`
+}
+
+// DeletionPolicy decides what happens to an issue when its GithubIssue is
+// deleted.
+// +kubebuilder:validation:Enum=Close;Lock;Orphan
+type DeletionPolicy string
+
+const (
+	// DeletionPolicyClose closes the issue with a comment.
+	DeletionPolicyClose DeletionPolicy = "Close"
+	// DeletionPolicyLock locks the conversation on the issue.
+	DeletionPolicyLock DeletionPolicy = "Lock"
+	// DeletionPolicyOrphan leaves the issue untouched.
+	DeletionPolicyOrphan DeletionPolicy = "Orphan"
+)
+
+// Condition types reported on GithubIssues.
+const (
+	// ConditionReady is True when the issue is synced and nothing keeps
+	// the operator from syncing it. It summarizes the other conditions.
+	ConditionReady = "Ready"
+
+	// ConditionSynced is True when the issue matches the current spec.
+	ConditionSynced = "Synced"
+
+	// ConditionCredentialsValid is False while the GitHub credentials of
+	// the resource are missing or rejected.
+	ConditionCredentialsValid = "CredentialsValid"
+
+	// ConditionRepositoryAccessible is False while spec.repository is
+	// invalid or cannot be accessed with the credentials in use.
+	ConditionRepositoryAccessible = "RepositoryAccessible"
+
+	// ConditionRateLimited is True while GitHub's rate limit keeps the
+	// issue from being synced.
+	ConditionRateLimited = "RateLimited"
+
+	// ConditionDeletionBlocked is True while the issue could not be cleaned
+	// up according to spec.deletionPolicy, which keeps the resource from
+	// being deleted.
+	ConditionDeletionBlocked = "DeletionBlocked"
+
+	// ConditionAssigneesValid is False while some of spec.assignees cannot
+	// be assigned issues in the repository.
+	ConditionAssigneesValid = "AssigneesValid"
+)
+
 // GithubIssueStatus defines the observed state of GithubIssue
 type GithubIssueStatus struct {
 	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
 	// Important: Run "make" to regenerate code after modifying this file
-	State       string `json:"state,omitempty"`
-	LastUpdated string `json:"lastupdated,omitempty"`
+	State       string       `json:"state,omitempty"`
+	LastUpdated *metav1.Time `json:"lastupdated,omitempty"`
+
+	// StateReason is why the issue was closed or reopened.
+	StateReason string `json:"stateReason,omitempty"`
+
+	// IssueNumber is the number of the issue this resource is bound to.
+	IssueNumber int `json:"issueNumber,omitempty"`
+
+	// HTMLURL is the web address of the issue.
+	// +optional
+	HTMLURL string `json:"htmlURL,omitempty"`
+
+	// Author is the login of the user who opened the issue.
+	// +optional
+	Author string `json:"author,omitempty"`
+
+	// CreatedAt is when the issue was opened.
+	// +optional
+	CreatedAt *metav1.Time `json:"createdAt,omitempty"`
+
+	// ClosedAt is when the issue was last closed, if it is closed.
+	// +optional
+	ClosedAt *metav1.Time `json:"closedAt,omitempty"`
+
+	// ClosedBy is the login of the user who closed the issue, if it is
+	// closed.
+	// +optional
+	ClosedBy string `json:"closedBy,omitempty"`
+
+	// Comments is the number of comments on the issue.
+	// +optional
+	Comments int `json:"comments,omitempty"`
+
+	// Labels are the names of all labels on the issue, whoever applied
+	// them.
+	// +optional
+	Labels []string `json:"labels,omitempty"`
+
+	// ObservedGeneration is the generation of the spec last synced to the
+	// issue. The generation each condition was last set for is recorded on
+	// the condition.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// ManagedLabels are the labels the operator added to the issue, which
+	// it removes again when they are dropped from spec.labels.
+	// +optional
+	ManagedLabels []string `json:"managedLabels,omitempty"`
+
+	// ManagedAssignees are the logins the operator assigned the issue to,
+	// which it unassigns again when they are dropped from spec.assignees.
+	// +optional
+	ManagedAssignees []string `json:"managedAssignees,omitempty"`
+
+	// Milestone is the milestone the issue is in.
+	// +optional
+	Milestone *MilestoneStatus `json:"milestone,omitempty"`
+
+	// Message explains why the resource could not be synced, if it could not.
+	Message string `json:"message,omitempty"`
+
+	// Conditions describe the state of the resource in detail.
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// MilestoneStatus identifies the milestone an issue is in.
+type MilestoneStatus struct {
+	// Number of the milestone in the repository.
+	Number int `json:"number"`
+	// Title of the milestone.
+	Title string `json:"title"`
 }
 
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
+// +kubebuilder:resource:shortName=ghi
+// +kubebuilder:printcolumn:name="Number",type=integer,JSONPath=`.status.issueNumber`
 // +kubebuilder:printcolumn:name="Title",type=string,JSONPath=`.spec.title`
 // +kubebuilder:printcolumn:name="State",type=string,JSONPath=`.status.state`
-// +kubebuilder:printcolumn:name="LastUpdated",type=string,JSONPath=`.status.lastupdated`
+// +kubebuilder:printcolumn:name="LastUpdated",type=date,JSONPath=`.status.lastupdated`
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+// +kubebuilder:printcolumn:name="URL",type=string,JSONPath=`.status.htmlURL`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 
 // GithubIssue is the Schema for the githubissues API
 type GithubIssue struct {
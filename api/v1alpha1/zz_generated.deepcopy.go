@@ -21,16 +21,32 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CredentialsReference) DeepCopyInto(out *CredentialsReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CredentialsReference.
+func (in *CredentialsReference) DeepCopy() *CredentialsReference {
+	if in == nil {
+		return nil
+	}
+	out := new(CredentialsReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GithubIssue) DeepCopyInto(out *GithubIssue) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
-	out.Status = in.Status
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GithubIssue.
@@ -86,6 +102,26 @@ func (in *GithubIssueList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GithubIssueSpec) DeepCopyInto(out *GithubIssueSpec) {
 	*out = *in
+	if in.CredentialsRef != nil {
+		in, out := &in.CredentialsRef, &out.CredentialsRef
+		*out = new(CredentialsReference)
+		**out = **in
+	}
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make([]Label, len(*in))
+		copy(*out, *in)
+	}
+	if in.Assignees != nil {
+		in, out := &in.Assignees, &out.Assignees
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Milestone != nil {
+		in, out := &in.Milestone, &out.Milestone
+		*out = new(Milestone)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GithubIssueSpec.
@@ -101,6 +137,45 @@ func (in *GithubIssueSpec) DeepCopy() *GithubIssueSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GithubIssueStatus) DeepCopyInto(out *GithubIssueStatus) {
 	*out = *in
+	if in.LastUpdated != nil {
+		in, out := &in.LastUpdated, &out.LastUpdated
+		*out = (*in).DeepCopy()
+	}
+	if in.CreatedAt != nil {
+		in, out := &in.CreatedAt, &out.CreatedAt
+		*out = (*in).DeepCopy()
+	}
+	if in.ClosedAt != nil {
+		in, out := &in.ClosedAt, &out.ClosedAt
+		*out = (*in).DeepCopy()
+	}
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ManagedLabels != nil {
+		in, out := &in.ManagedLabels, &out.ManagedLabels
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ManagedAssignees != nil {
+		in, out := &in.ManagedAssignees, &out.ManagedAssignees
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Milestone != nil {
+		in, out := &in.Milestone, &out.Milestone
+		*out = new(MilestoneStatus)
+		**out = **in
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GithubIssueStatus.
@@ -112,3 +187,52 @@ func (in *GithubIssueStatus) DeepCopy() *GithubIssueStatus {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Label) DeepCopyInto(out *Label) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Label.
+func (in *Label) DeepCopy() *Label {
+	if in == nil {
+		return nil
+	}
+	out := new(Label)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Milestone) DeepCopyInto(out *Milestone) {
+	*out = *in
+	if in.DueOn != nil {
+		in, out := &in.DueOn, &out.DueOn
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Milestone.
+func (in *Milestone) DeepCopy() *Milestone {
+	if in == nil {
+		return nil
+	}
+	out := new(Milestone)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MilestoneStatus) DeepCopyInto(out *MilestoneStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MilestoneStatus.
+func (in *MilestoneStatus) DeepCopy() *MilestoneStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(MilestoneStatus)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -17,25 +17,36 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"crypto/tls"
 	"flag"
+	"fmt"
+	"net/http"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
 	"sigs.k8s.io/controller-runtime/pkg/metrics/filters"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
 	trainingv1alpha1 "github.com/zszabo-rh/issues-operator/api/v1alpha1"
+	"github.com/zszabo-rh/issues-operator/gitclient"
 	"github.com/zszabo-rh/issues-operator/internal/controller"
 	// +kubebuilder:scaffold:imports
 )
@@ -50,6 +61,8 @@ func init() {
 
 	utilruntime.Must(trainingv1alpha1.AddToScheme(scheme))
 	// +kubebuilder:scaffold:scheme
+
+	utilruntime.Must(gitclient.RegisterMetrics(metrics.Registry))
 }
 
 func main() {
@@ -58,6 +71,22 @@ func main() {
 	var probeAddr string
 	var secureMetrics bool
 	var enableHTTP2 bool
+	var issueTracker string
+	var clusterID string
+	var githubTimeout time.Duration
+	var githubCAFile string
+	var githubCASecret string
+	var githubHostsConfig string
+	var githubHosts []gitclient.HostConfig
+	var githubAppSecret string
+	var githubAppHost string
+	var defaultCredentialsSecret string
+	var fallbackToOperatorCredentials bool
+	var checkCredentials bool
+	var credentialsExpiryWarning time.Duration
+	var readOnly bool
+	var resyncPeriod time.Duration
+	var identityMap string
 	var tlsOpts []func(*tls.Config)
 	flag.StringVar(&metricsAddr, "metrics-bind-address", "0", "The address the metrics endpoint binds to. "+
 		"Use :8443 for HTTPS or :8080 for HTTP, or leave as 0 to disable the metrics service.")
@@ -69,6 +98,59 @@ func main() {
 		"If set, the metrics endpoint is served securely via HTTPS. Use --metrics-secure=false to use HTTP instead.")
 	flag.BoolVar(&enableHTTP2, "enable-http2", false,
 		"If set, HTTP/2 will be enabled for the metrics and webhook servers")
+	flag.StringVar(&issueTracker, "issue-tracker", "github",
+		"The issue tracker to reconcile against. Use 'memory' to keep issues in memory and run without GitHub access.")
+	flag.StringVar(&clusterID, "cluster-id", "",
+		"Identifies this cluster in the owner marker of managed issues. "+
+			"Set it when operators in several clusters file issues into the same repository.")
+	flag.DurationVar(&githubTimeout, "github-timeout", gitclient.DefaultTimeout,
+		"The maximum time a single request to the GitHub API may take.")
+	flag.StringVar(&githubCAFile, "github-ca-file", "",
+		"Path to a PEM bundle of additional certificate authorities to trust when connecting to GitHub, "+
+			"e.g. the CA of an egress proxy. Proxies are taken from HTTPS_PROXY, HTTP_PROXY and NO_PROXY.")
+	flag.StringVar(&githubCASecret, "github-ca-secret", "",
+		"A Secret, given as <namespace>/<name>, whose ca.crt key holds additional certificate authorities "+
+			"to trust when connecting to GitHub.")
+	flag.StringVar(&githubHostsConfig, "github-hosts-config", "",
+		"Path to a YAML file listing GitHub hosts with their API URL and credentials. Tokens read from a "+
+			"tokenFile or a tokenExec credential plugin are rotated without restarting the operator, and a "+
+			"tokenPool spreads requests over several tokens.")
+	flag.Func("github-host",
+		"A GitHub Enterprise Server host to manage issues on, as <host> or <host>=<api-url>. The API URL defaults "+
+			"to https://<host>/api/v3 and the token is read from GITTOKEN_<HOST>, e.g. GITTOKEN_GITHUB_EXAMPLE_COM. "+
+			"May be repeated.",
+		func(value string) error {
+			host, apiURL, _ := strings.Cut(value, "=")
+			githubHosts = append(githubHosts, gitclient.HostConfig{Host: host, APIURL: apiURL})
+			return nil
+		})
+	flag.StringVar(&githubAppSecret, "github-app-secret", "",
+		"A Secret, given as <namespace>/<name>, holding the app-id and private-key of a GitHub App to "+
+			"authenticate as instead of a personal access token. Issues are then authored by the app.")
+	flag.StringVar(&githubAppHost, "github-app-host", gitclient.DefaultHost,
+		"The GitHub host the app given by --github-app-secret is registered on.")
+	flag.StringVar(&defaultCredentialsSecret, "default-credentials-secret", "",
+		"The name of a Secret whose token key holds the GitHub token for GithubIssues without spec.credentialsRef, "+
+			"looked up in each resource's namespace. Resources in namespaces without it are not synced unless "+
+			"--fallback-to-operator-credentials is set.")
+	flag.BoolVar(&fallbackToOperatorCredentials, "fallback-to-operator-credentials", false,
+		"Let GithubIssues in namespaces without the --default-credentials-secret use the operator's own credentials. "+
+			"Leave it unset on multi-tenant clusters.")
+	flag.BoolVar(&checkCredentials, "check-credentials", true,
+		"Validate the operator's GitHub credentials periodically and report the operator as not ready while "+
+			"any of them is missing, rejected or expired. Disable it when all issues use credentials from Secrets.")
+	flag.DurationVar(&credentialsExpiryWarning, "credentials-expiry-warning", controller.DefaultExpiryWarning,
+		"How long before the operator's GitHub credentials expire to start warning about it.")
+	flag.BoolVar(&readOnly, "read-only", false,
+		"Never create or edit issues, only report the state of bound ones. The operator also switches to "+
+			"read-only mode for hosts whose credentials lack write access.")
+	flag.DurationVar(&resyncPeriod, "resync-period", 10*time.Minute,
+		"How often synced issues are checked for changes made on GitHub, such as someone reopening them. "+
+			"Use 0 to only sync when a GithubIssue changes.")
+	flag.StringVar(&identityMap, "identity-map", "",
+		"A ConfigMap, given as <namespace>/<name>, mapping GitHub logins to Kubernetes user names so that "+
+			"spec.assignees may name either. Each key is a GitHub login and its value lists that user's "+
+			"Kubernetes user names separated by whitespace.")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -144,15 +226,94 @@ func main() {
 		os.Exit(1)
 	}
 
+	var newTracker gitclient.TrackerFactory
+	var credentialsMonitor *controller.CredentialsMonitor
+	switch issueTracker {
+	case "github":
+		transportConfig := gitclient.TransportConfig{CAFile: githubCAFile}
+		if githubCASecret != "" {
+			transportConfig.CAData, err = readSecretKey(mgr.GetAPIReader(), githubCASecret, "ca.crt")
+			if err != nil {
+				setupLog.Error(err, "unable to read GitHub CA bundle")
+				os.Exit(1)
+			}
+		}
+		httpClient, err := gitclient.NewHTTPClient(transportConfig)
+		if err != nil {
+			setupLog.Error(err, "unable to set up GitHub HTTP client")
+			os.Exit(1)
+		}
+		hosts, err := loadHosts(githubHostsConfig, githubHosts)
+		if err != nil {
+			setupLog.Error(err, "unable to load GitHub host configuration")
+			os.Exit(1)
+		}
+		trackerOpts := []gitclient.Option{
+			gitclient.WithTimeout(githubTimeout),
+			gitclient.WithHTTPClient(httpClient),
+			gitclient.WithHosts(hosts),
+		}
+		var app *gitclient.App
+		if githubAppSecret != "" {
+			app, err = loadApp(mgr.GetAPIReader(), githubAppSecret, githubAppHost, hosts, httpClient)
+			if err != nil {
+				setupLog.Error(err, "unable to load GitHub App credentials")
+				os.Exit(1)
+			}
+			setupLog.Info("authenticating as a GitHub App", "host", app.Host())
+			trackerOpts = append(trackerOpts, gitclient.WithApp(app))
+		}
+		newTracker = gitclient.NewGitHubTrackerFactory(trackerOpts...)
+		if checkCredentials {
+			credentialsMonitor = &controller.CredentialsMonitor{
+				Checks:        credentialChecks(hosts, app, httpClient),
+				ExpiryWarning: credentialsExpiryWarning,
+			}
+			if err := mgr.Add(credentialsMonitor); err != nil {
+				setupLog.Error(err, "unable to set up GitHub credentials checks")
+				os.Exit(1)
+			}
+		}
+	case "memory":
+		setupLog.Info("using in-memory issue tracker, no issues will be filed on GitHub")
+		newTracker = gitclient.NewMemoryTrackerFactory()
+	default:
+		setupLog.Error(fmt.Errorf("unknown issue tracker %q", issueTracker), "invalid --issue-tracker")
+		os.Exit(1)
+	}
+
+	var identityMapRef types.NamespacedName
+	if identityMap != "" {
+		namespace, name, ok := strings.Cut(identityMap, "/")
+		if !ok || namespace == "" || name == "" {
+			setupLog.Error(fmt.Errorf("invalid config map reference %q, expected <namespace>/<name>", identityMap), "invalid --identity-map")
+			os.Exit(1)
+		}
+		identityMapRef = types.NamespacedName{Namespace: namespace, Name: name}
+	}
+
 	if err = (&controller.GithubIssueReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+		Client:                        mgr.GetClient(),
+		Scheme:                        mgr.GetScheme(),
+		NewTracker:                    newTracker,
+		ClusterID:                     clusterID,
+		DefaultCredentialsSecret:      defaultCredentialsSecret,
+		FallbackToOperatorCredentials: fallbackToOperatorCredentials,
+		Recorder:                      mgr.GetEventRecorderFor("githubissue-controller"),
+		Credentials:                   credentialsMonitor,
+		ReadOnly:                      readOnly,
+		ResyncPeriod:                  resyncPeriod,
+		IdentityMap:                   identityMapRef,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "GithubIssue")
 		os.Exit(1)
 	}
 	// +kubebuilder:scaffold:builder
 
+	// The issue analytics are computed from the cached GithubIssues on
+	// every scrape of the metrics endpoint.
+	metrics.Registry.MustRegister(controller.NewIssueCollector(mgr.GetClient()))
+
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
 		setupLog.Error(err, "unable to set up health check")
 		os.Exit(1)
@@ -161,6 +322,12 @@ func main() {
 		setupLog.Error(err, "unable to set up ready check")
 		os.Exit(1)
 	}
+	if credentialsMonitor != nil {
+		if err := mgr.AddReadyzCheck("github-credentials", credentialsMonitor.Checker); err != nil {
+			setupLog.Error(err, "unable to set up ready check")
+			os.Exit(1)
+		}
+	}
 
 	setupLog.Info("starting manager")
 	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
@@ -168,3 +335,93 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+// loadHosts builds the GitHub host configuration from the hosts config file,
+// if any, and the hosts given on the command line.
+func loadHosts(path string, extra []gitclient.HostConfig) (*gitclient.Hosts, error) {
+	hosts, err := gitclient.NewHosts()
+	if path != "" {
+		hosts, err = gitclient.LoadHostsFile(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, cfg := range extra {
+		if err := hosts.Add(cfg); err != nil {
+			return nil, err
+		}
+	}
+	return hosts, nil
+}
+
+// credentialChecks returns a check for every credential configured for hosts.
+// The credentials of the app's host are replaced by the app.
+func credentialChecks(hosts *gitclient.Hosts, app *gitclient.App, httpClient *http.Client) []controller.CredentialCheck {
+	var checks []controller.CredentialCheck
+	for _, cred := range hosts.Credentials() {
+		if app != nil && cred.Host == app.Host() {
+			continue
+		}
+		checks = append(checks, controller.CredentialCheck{
+			Host: cred.Host,
+			Check: func(ctx context.Context) (gitclient.CredentialInfo, error) {
+				return gitclient.CheckCredential(ctx, httpClient, cred)
+			},
+		})
+	}
+	if app != nil {
+		checks = append(checks, controller.CredentialCheck{Host: app.Host(), Check: app.Check})
+	}
+	return checks
+}
+
+// loadApp reads the GitHub App registered on host from the Secret named by
+// ref.
+func loadApp(reader client.Reader, ref string, host string, hosts *gitclient.Hosts, httpClient *http.Client) (*gitclient.App, error) {
+	hostCfg, err := hosts.Lookup(host)
+	if err != nil {
+		return nil, err
+	}
+	appID, err := readSecretKey(reader, ref, "app-id")
+	if err != nil {
+		return nil, err
+	}
+	id, err := strconv.ParseInt(strings.TrimSpace(string(appID)), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("secret %s: invalid app-id: %w", ref, err)
+	}
+	privateKey, err := readSecretKey(reader, ref, "private-key")
+	if err != nil {
+		return nil, err
+	}
+	return gitclient.NewApp(gitclient.AppConfig{
+		ID:         id,
+		PrivateKey: privateKey,
+		Host:       hostCfg.Host,
+		APIURL:     hostCfg.APIURL,
+		HTTPClient: httpClient,
+	})
+}
+
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get
+
+// readSecretKey returns the value of key in the Secret named by ref, given as
+// <namespace>/<name>. It is meant for reading configuration at startup, before
+// the manager's cache is running.
+func readSecretKey(reader client.Reader, ref string, key string) ([]byte, error) {
+	namespace, name, ok := strings.Cut(ref, "/")
+	if !ok || namespace == "" || name == "" {
+		return nil, fmt.Errorf("invalid secret reference %q, expected <namespace>/<name>", ref)
+	}
+
+	secret := &corev1.Secret{}
+	err := reader.Get(context.Background(), types.NamespacedName{Namespace: namespace, Name: name}, secret)
+	if err != nil {
+		return nil, err
+	}
+	value, ok := secret.Data[key]
+	if !ok {
+		return nil, fmt.Errorf("secret %s has no key %q", ref, key)
+	}
+	return value, nil
+}
@@ -0,0 +1,267 @@
+package gitclient
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// appJWTLifetime is how long the JWTs signed by an App are valid. GitHub
+	// rejects JWTs valid for more than ten minutes.
+	appJWTLifetime = 9 * time.Minute
+	// appClockSkew backdates the JWTs signed by an App to allow for clocks
+	// running ahead of GitHub's.
+	appClockSkew = time.Minute
+	// installationTokenRefresh is how long before their expiry installation
+	// tokens are replaced, so that a request never starts with a token that
+	// expires while it is in flight.
+	installationTokenRefresh = 5 * time.Minute
+)
+
+// AppConfig identifies a GitHub App and where it is installed.
+type AppConfig struct {
+	// ID is the app ID shown on the app's settings page.
+	ID int64
+	// PrivateKey is a PEM encoded private key generated for the app.
+	PrivateKey []byte
+	// Host is the GitHub host the app is registered on. It defaults to
+	// DefaultHost.
+	Host string
+	// APIURL is the REST API endpoint of Host. It defaults to DefaultBaseURL
+	// for github.com and to https://<host>/api/v3 otherwise.
+	APIURL string
+	// HTTPClient is used to request installation tokens. It defaults to the
+	// client shared by GitClients.
+	HTTPClient *http.Client
+}
+
+// App authenticates as a GitHub App. It exchanges JWTs signed with the app's
+// private key for installation tokens, one per repository owner, and caches
+// them until shortly before they expire.
+type App struct {
+	id         int64
+	key        *rsa.PrivateKey
+	host       string
+	apiURL     string
+	httpClient *http.Client
+
+	// mu guards installations. Tokens are requested under the lock of
+	// their installation only, so that a slow owner does not hold up the
+	// others.
+	mu            sync.Mutex
+	installations map[string]*installation
+}
+
+// installation is the installation of an App for one repository owner.
+type installation struct {
+	mu sync.Mutex
+	// id is 0 until the installation has been looked up.
+	id        int64
+	token     string
+	expiresAt time.Time
+}
+
+// NewApp returns an App for cfg.
+func NewApp(cfg AppConfig) (*App, error) {
+	if cfg.ID <= 0 {
+		return nil, errors.New("invalid GitHub App: missing app ID")
+	}
+	key, err := parsePrivateKey(cfg.PrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid GitHub App private key: %w", err)
+	}
+
+	host := strings.ToLower(cfg.Host)
+	if host == "" {
+		host = DefaultHost
+	}
+	apiURL := cfg.APIURL
+	if apiURL == "" {
+		apiURL = defaultAPIURL(host)
+	}
+	httpClient := cfg.HTTPClient
+	if httpClient == nil {
+		httpClient = defaultHTTPClient
+	}
+
+	return &App{
+		id:            cfg.ID,
+		key:           key,
+		host:          host,
+		apiURL:        strings.TrimSuffix(apiURL, "/"),
+		httpClient:    httpClient,
+		installations: map[string]*installation{},
+	}, nil
+}
+
+// Host returns the GitHub host the app is registered on.
+func (a *App) Host() string {
+	return a.host
+}
+
+// TokenSource returns the installation tokens of the app for the repositories
+// of owner.
+func (a *App) TokenSource(owner string) TokenSource {
+	return &appTokenSource{app: a, owner: owner}
+}
+
+type appTokenSource struct {
+	app   *App
+	owner string
+}
+
+func (s *appTokenSource) Token(ctx context.Context) (string, error) {
+	return s.app.installationToken(ctx, s.owner)
+}
+
+func (s *appTokenSource) credentialName(string) string {
+	return fmt.Sprintf("app:%d/%s", s.app.id, strings.ToLower(s.owner))
+}
+
+// invalidate drops token from the cache of the installation when GitHub
+// rejected it, e.g. because it was revoked, so that the next request gets a
+// new one.
+func (s *appTokenSource) invalidate(token string) {
+	s.app.mu.Lock()
+	inst := s.app.installations[strings.ToLower(s.owner)]
+	s.app.mu.Unlock()
+	if inst == nil {
+		return
+	}
+
+	inst.mu.Lock()
+	defer inst.mu.Unlock()
+	if inst.token == token {
+		inst.token = ""
+	}
+}
+
+// installationToken returns a token for the installation of the app on owner,
+// requesting a new one when the cached token is about to expire.
+func (a *App) installationToken(ctx context.Context, owner string) (string, error) {
+	key := strings.ToLower(owner)
+
+	a.mu.Lock()
+	inst := a.installations[key]
+	if inst == nil {
+		inst = &installation{}
+		a.installations[key] = inst
+	}
+	a.mu.Unlock()
+
+	inst.mu.Lock()
+	defer inst.mu.Unlock()
+
+	if inst.token != "" && time.Until(inst.expiresAt) > installationTokenRefresh {
+		return inst.token, nil
+	}
+
+	jwt, err := a.jwt(time.Now())
+	if err != nil {
+		return "", err
+	}
+
+	if inst.id == 0 {
+		id, err := a.installationID(ctx, jwt, owner)
+		if err != nil {
+			return "", err
+		}
+		inst.id = id
+	}
+
+	var resp struct {
+		Token     string    `json:"token"`
+		ExpiresAt time.Time `json:"expires_at"`
+	}
+	tokenURL := fmt.Sprintf("%s/app/installations/%d/access_tokens", a.apiURL, inst.id)
+	if _, err := doRequest(ctx, a.httpClient, http.MethodPost, tokenURL, jwt, nil, &resp); err != nil {
+		if IsNotFound(err) {
+			// The app was uninstalled; look the installation up again next
+			// time in case it was reinstalled.
+			inst.id = 0
+		}
+		return "", fmt.Errorf("requesting installation token for %s: %w", owner, err)
+	}
+
+	inst.token = resp.Token
+	inst.expiresAt = resp.ExpiresAt
+	return inst.token, nil
+}
+
+// installationID looks up the installation of the app on owner, which may be
+// an organization or a user.
+func (a *App) installationID(ctx context.Context, jwt string, owner string) (int64, error) {
+	var resp struct {
+		ID int64 `json:"id"`
+	}
+	_, err := doRequest(ctx, a.httpClient, http.MethodGet, a.apiURL+"/orgs/"+url.PathEscape(owner)+"/installation", jwt, nil, &resp)
+	if IsNotFound(err) {
+		_, err = doRequest(ctx, a.httpClient, http.MethodGet, a.apiURL+"/users/"+url.PathEscape(owner)+"/installation", jwt, nil, &resp)
+	}
+	if err != nil {
+		if IsNotFound(err) {
+			return 0, fmt.Errorf("GitHub App %d is not installed for %s: %w", a.id, owner, err)
+		}
+		return 0, fmt.Errorf("looking up GitHub App installation for %s: %w", owner, err)
+	}
+	return resp.ID, nil
+}
+
+// jwt returns a JWT authenticating as the app, signed with RS256.
+func (a *App) jwt(now time.Time) (string, error) {
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	if err != nil {
+		return "", err
+	}
+	claims, err := json.Marshal(map[string]int64{
+		"iat": now.Add(-appClockSkew).Unix(),
+		"exp": now.Add(appJWTLifetime).Unix(),
+		"iss": a.id,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(signed))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, a.key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", err
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// parsePrivateKey parses a PEM encoded RSA key in PKCS #1 form, as GitHub
+// generates them, or in PKCS #8 form.
+func parsePrivateKey(data []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM data found")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("expected an RSA key, got %T", key)
+	}
+	return rsaKey, nil
+}
@@ -0,0 +1,213 @@
+package gitclient_test
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/zszabo-rh/issues-operator/gitclient"
+)
+
+var _ = Describe("GitHub App", func() {
+
+	var (
+		server      *httptest.Server
+		key         *rsa.PrivateKey
+		app         *gitclient.App
+		tokenCalls  atomic.Int32
+		tokenExpiry time.Duration
+		issueAuth   chan string
+		slowStarted chan struct{}
+		slowRelease chan struct{}
+		revoked     string
+	)
+
+	// verifyJWT checks that the request is authenticated as app 42.
+	verifyJWT := func(r *http.Request) {
+		defer GinkgoRecover()
+		parts := strings.Split(strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "), ".")
+		Expect(parts).To(HaveLen(3))
+
+		digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+		signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+		Expect(err).ToNot(HaveOccurred())
+		Expect(rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, digest[:], signature)).To(Succeed())
+
+		payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+		Expect(err).ToNot(HaveOccurred())
+		var claims struct {
+			Iat int64 `json:"iat"`
+			Exp int64 `json:"exp"`
+			Iss int64 `json:"iss"`
+		}
+		Expect(json.Unmarshal(payload, &claims)).To(Succeed())
+		Expect(claims.Iss).To(Equal(int64(42)))
+		Expect(time.Unix(claims.Iat, 0)).To(BeTemporally("<", time.Now()))
+		Expect(time.Unix(claims.Exp, 0)).To(BeTemporally("<=", time.Now().Add(10*time.Minute)))
+	}
+
+	BeforeEach(func() {
+		var err error
+		key, err = rsa.GenerateKey(rand.Reader, 2048)
+		Expect(err).ToNot(HaveOccurred())
+		tokenCalls.Store(0)
+		tokenExpiry = time.Hour
+		issueAuth = make(chan string, 10)
+		slowStarted = make(chan struct{}, 1)
+		slowRelease = make(chan struct{})
+		revoked = ""
+
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch {
+			case r.URL.Path == "/orgs/myuser/installation":
+				w.WriteHeader(http.StatusNotFound)
+			case r.URL.Path == "/orgs/slowowner/installation":
+				slowStarted <- struct{}{}
+				<-slowRelease
+				w.WriteHeader(http.StatusNotFound)
+			case r.URL.Path == "/users/myuser/installation":
+				verifyJWT(r)
+				fmt.Fprint(w, `{"id": 7}`)
+			case r.Method == http.MethodPost && r.URL.Path == "/app/installations/7/access_tokens":
+				verifyJWT(r)
+				n := tokenCalls.Add(1)
+				expiresAt := time.Now().Add(tokenExpiry).UTC().Format(time.RFC3339)
+				fmt.Fprintf(w, `{"token": "ghs_%d", "expires_at": %q}`, n, expiresAt)
+			case r.URL.Path == "/repos/myuser/myrepo/issues/1":
+				issueAuth <- r.Header.Get("Authorization")
+				if revoked != "" && r.Header.Get("Authorization") == "Bearer "+revoked {
+					w.WriteHeader(http.StatusUnauthorized)
+					fmt.Fprint(w, `{"message": "Bad credentials"}`)
+					return
+				}
+				fmt.Fprint(w, `{"number": 1}`)
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+
+		app, err = gitclient.NewApp(gitclient.AppConfig{
+			ID:         42,
+			PrivateKey: pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}),
+			APIURL:     server.URL,
+		})
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		select {
+		case <-slowRelease:
+		default:
+			close(slowRelease)
+		}
+		server.Close()
+	})
+
+	It("should authenticate with an installation token for the repository owner", func() {
+		client, err := gitclient.NewGitClient("myuser/myrepo", gitclient.WithApp(app))
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = client.GetIssue(ctx, 1)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(issueAuth).To(Receive(Equal("Bearer ghs_1")))
+	})
+
+	It("should reuse the installation token until it is about to expire", func() {
+		client, err := gitclient.NewGitClient("myuser/myrepo", gitclient.WithApp(app))
+		Expect(err).ToNot(HaveOccurred())
+
+		for i := 0; i < 3; i++ {
+			_, err = client.GetIssue(ctx, 1)
+			Expect(err).ToNot(HaveOccurred())
+		}
+		Expect(tokenCalls.Load()).To(Equal(int32(1)))
+	})
+
+	It("should refresh installation tokens close to their expiry", func() {
+		tokenExpiry = time.Minute
+		client, err := gitclient.NewGitClient("myuser/myrepo", gitclient.WithApp(app))
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = client.GetIssue(ctx, 1)
+		Expect(err).ToNot(HaveOccurred())
+		_, err = client.GetIssue(ctx, 1)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(tokenCalls.Load()).To(Equal(int32(2)))
+		Expect(issueAuth).To(Receive(Equal("Bearer ghs_1")))
+		Expect(issueAuth).To(Receive(Equal("Bearer ghs_2")))
+	})
+
+	It("should request a new installation token when GitHub rejects the cached one", func() {
+		client, err := gitclient.NewGitClient("myuser/myrepo", gitclient.WithApp(app))
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = client.GetIssue(ctx, 1)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(issueAuth).To(Receive(Equal("Bearer ghs_1")))
+
+		revoked = "ghs_1"
+		_, err = client.GetIssue(ctx, 1)
+		Expect(gitclient.IsUnauthorized(err)).To(BeTrue())
+		Expect(issueAuth).To(Receive(Equal("Bearer ghs_1")))
+
+		_, err = client.GetIssue(ctx, 1)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(issueAuth).To(Receive(Equal("Bearer ghs_2")))
+		Expect(tokenCalls.Load()).To(Equal(int32(2)))
+	})
+
+	It("should not hold up other owners while one owner's token is requested", func() {
+		slow := make(chan error, 1)
+		go func() {
+			_, err := app.TokenSource("slowowner").Token(ctx)
+			slow <- err
+		}()
+		Eventually(slowStarted).Should(Receive())
+
+		tokens := make(chan string, 1)
+		go func() {
+			defer GinkgoRecover()
+			token, err := app.TokenSource("myuser").Token(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			tokens <- token
+		}()
+		Eventually(tokens).Should(Receive(Equal("ghs_1")))
+		Expect(slow).NotTo(Receive())
+
+		close(slowRelease)
+		Eventually(slow).Should(Receive(MatchError(ContainSubstring("not installed for slowowner"))))
+	})
+
+	It("should report owners the app is not installed for", func() {
+		client, err := gitclient.NewGitClient("otheruser/myrepo", gitclient.WithApp(app))
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = client.GetIssue(ctx, 1)
+		Expect(err).To(MatchError(ContainSubstring("not installed for otheruser")))
+		Expect(gitclient.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("should not be used for repositories on other hosts", func() {
+		_, err := gitclient.NewGitClient("github.example.com:myuser/myrepo", gitclient.WithApp(app))
+		Expect(err).To(MatchError(ContainSubstring("not configured")))
+	})
+
+	It("should reject invalid private keys", func() {
+		_, err := gitclient.NewApp(gitclient.AppConfig{ID: 42, PrivateKey: []byte("not a key")})
+		Expect(err).To(MatchError(ContainSubstring("invalid GitHub App private key")))
+	})
+})
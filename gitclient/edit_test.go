@@ -0,0 +1,204 @@
+package gitclient_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/zszabo-rh/issues-operator/gitclient"
+)
+
+var _ = Describe("Editing issues", func() {
+
+	It("should send only the changed fields in one request", func() {
+		var payload map[string]any
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer GinkgoRecover()
+			Expect(r.Method).To(Equal(http.MethodPatch))
+			Expect(r.URL.Path).To(Equal("/repos/myuser/myrepo/issues/7"))
+			Expect(json.NewDecoder(r.Body).Decode(&payload)).To(Succeed())
+			fmt.Fprint(w, `{"number": 7, "state": "closed", "state_reason": "not_planned"}`)
+		}))
+		defer server.Close()
+
+		client, err := gitclient.NewGitClient("myuser/myrepo", gitclient.WithBaseURL(server.URL), gitclient.WithToken("abc123"))
+		Expect(err).ToNot(HaveOccurred())
+
+		issue, err := client.EditIssue(ctx, 7, gitclient.IssueEdit{State: "closed", StateReason: "not_planned"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(payload).To(Equal(map[string]any{"state": "closed", "state_reason": "not_planned"}))
+		Expect(issue.Status).To(Equal("closed"))
+		Expect(issue.StateReason).To(Equal("not_planned"))
+	})
+
+	It("should decode the metadata of an issue", func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `{
+				"number": 7,
+				"state": "closed",
+				"html_url": "https://github.com/myuser/myrepo/issues/7",
+				"user": {"login": "octocat"},
+				"created_at": "2024-05-01T10:00:00Z",
+				"updated_at": "2024-05-03T10:00:00Z",
+				"closed_at": "2024-05-02T10:00:00Z",
+				"closed_by": {"login": "hubot"},
+				"comments": 3,
+				"labels": [{"name": "bug", "color": "d73a4a"}]
+			}`)
+		}))
+		defer server.Close()
+
+		client, err := gitclient.NewGitClient("myuser/myrepo", gitclient.WithBaseURL(server.URL), gitclient.WithToken("abc123"))
+		Expect(err).ToNot(HaveOccurred())
+
+		issue, err := client.GetIssue(ctx, 7)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(issue.HTMLURL).To(Equal("https://github.com/myuser/myrepo/issues/7"))
+		Expect(issue.User).To(Equal(&gitclient.GitUser{Login: "octocat"}))
+		Expect(issue.CreatedAt).To(Equal("2024-05-01T10:00:00Z"))
+		Expect(issue.ClosedAt).To(Equal("2024-05-02T10:00:00Z"))
+		Expect(issue.ClosedBy).To(Equal(&gitclient.GitUser{Login: "hubot"}))
+		Expect(issue.Comments).To(Equal(3))
+		Expect(issue.Labels).To(Equal([]gitclient.GitLabel{{Name: "bug", Color: "d73a4a"}}))
+	})
+
+	It("should comment on and lock an issue", func() {
+		var requests []string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests = append(requests, r.Method+" "+r.URL.Path)
+			if r.URL.Path == "/repos/myuser/myrepo/issues/7/lock" {
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprint(w, `{"id": 42, "body": "bye"}`)
+		}))
+		defer server.Close()
+
+		client, err := gitclient.NewGitClient("myuser/myrepo", gitclient.WithBaseURL(server.URL), gitclient.WithToken("abc123"))
+		Expect(err).ToNot(HaveOccurred())
+
+		comment, err := client.AddComment(ctx, 7, "bye")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(comment.Id).To(Equal(int64(42)))
+		Expect(client.LockIssue(ctx, 7, "resolved")).To(Succeed())
+		Expect(requests).To(Equal([]string{"POST /repos/myuser/myrepo/issues/7/comments", "PUT /repos/myuser/myrepo/issues/7/lock"}))
+	})
+
+	It("should look up and create repository labels", func() {
+		var created map[string]any
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer GinkgoRecover()
+			switch r.Method + " " + r.URL.EscapedPath() {
+			case "GET /repos/myuser/myrepo/labels/good%20first%20issue":
+				w.WriteHeader(http.StatusNotFound)
+				fmt.Fprint(w, `{"message": "Not Found"}`)
+			case "POST /repos/myuser/myrepo/labels":
+				Expect(json.NewDecoder(r.Body).Decode(&created)).To(Succeed())
+				w.WriteHeader(http.StatusCreated)
+				fmt.Fprint(w, `{"name": "good first issue", "color": "7057ff", "description": "Easy"}`)
+			default:
+				Fail("unexpected request " + r.Method + " " + r.URL.EscapedPath())
+			}
+		}))
+		defer server.Close()
+
+		client, err := gitclient.NewGitClient("myuser/myrepo", gitclient.WithBaseURL(server.URL), gitclient.WithToken("abc123"))
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = client.GetLabel(ctx, "good first issue")
+		Expect(gitclient.IsNotFound(err)).To(BeTrue())
+		label, err := client.CreateLabel(ctx, gitclient.GitLabel{Name: "good first issue", Color: "7057ff", Description: "Easy"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(created).To(Equal(map[string]any{"name": "good first issue", "color": "7057ff", "description": "Easy"}))
+		Expect(label.Color).To(Equal("7057ff"))
+	})
+
+	It("should replace the labels of an issue only when asked to", func() {
+		var payloads []map[string]any
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer GinkgoRecover()
+			var payload map[string]any
+			Expect(json.NewDecoder(r.Body).Decode(&payload)).To(Succeed())
+			payloads = append(payloads, payload)
+			fmt.Fprint(w, `{"number": 7}`)
+		}))
+		defer server.Close()
+
+		client, err := gitclient.NewGitClient("myuser/myrepo", gitclient.WithBaseURL(server.URL), gitclient.WithToken("abc123"))
+		Expect(err).ToNot(HaveOccurred())
+
+		noLabels := []string{}
+		_, err = client.EditIssue(ctx, 7, gitclient.IssueEdit{Title: "retitled"})
+		Expect(err).ToNot(HaveOccurred())
+		_, err = client.EditIssue(ctx, 7, gitclient.IssueEdit{Labels: &noLabels})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(payloads).To(Equal([]map[string]any{{"title": "retitled"}, {"labels": []any{}}}))
+	})
+
+	It("should check whether users can be assigned issues", func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/repos/myuser/myrepo/assignees/octocat":
+				w.WriteHeader(http.StatusNoContent)
+			default:
+				w.WriteHeader(http.StatusNotFound)
+				fmt.Fprint(w, `{"message": "Not Found"}`)
+			}
+		}))
+		defer server.Close()
+
+		client, err := gitclient.NewGitClient("myuser/myrepo", gitclient.WithBaseURL(server.URL), gitclient.WithToken("abc123"))
+		Expect(err).ToNot(HaveOccurred())
+
+		ok, err := client.IsAssignable(ctx, "octocat")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ok).To(BeTrue())
+		ok, err = client.IsAssignable(ctx, "stranger")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ok).To(BeFalse())
+	})
+
+	It("should list milestones across pages and create missing ones", func() {
+		var created map[string]any
+		var server *httptest.Server
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer GinkgoRecover()
+			switch {
+			case r.Method == http.MethodPost:
+				Expect(r.URL.Path).To(Equal("/repos/myuser/myrepo/milestones"))
+				Expect(json.NewDecoder(r.Body).Decode(&created)).To(Succeed())
+				w.WriteHeader(http.StatusCreated)
+				fmt.Fprint(w, `{"number": 3, "title": "v3.0", "state": "open", "due_on": "2030-03-01T00:00:00Z"}`)
+			case r.URL.Query().Get("page") == "2":
+				fmt.Fprint(w, `[{"number": 2, "title": "v2.0", "state": "closed"}]`)
+			default:
+				Expect(r.URL.Path).To(Equal("/repos/myuser/myrepo/milestones"))
+				Expect(r.URL.Query().Get("state")).To(Equal("all"))
+				w.Header().Set("Link", `<`+server.URL+`/repos/myuser/myrepo/milestones?state=all&page=2>; rel="next"`)
+				fmt.Fprint(w, `[{"number": 1, "title": "v1.0", "state": "open"}]`)
+			}
+		}))
+		defer server.Close()
+
+		client, err := gitclient.NewGitClient("myuser/myrepo", gitclient.WithBaseURL(server.URL), gitclient.WithToken("abc123"))
+		Expect(err).ToNot(HaveOccurred())
+
+		milestones, err := client.ListMilestones(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(milestones).To(HaveLen(2))
+		Expect(milestones[1].Title).To(Equal("v2.0"))
+
+		dueOn := time.Date(2030, time.March, 1, 0, 0, 0, 0, time.UTC)
+		milestone, err := client.CreateMilestone(ctx, gitclient.GitMilestone{Title: "v3.0", DueOn: &dueOn})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(created).To(Equal(map[string]any{"title": "v3.0", "due_on": "2030-03-01T00:00:00Z"}))
+		Expect(milestone.Number).To(Equal(3))
+		Expect(milestone.DueOn.Equal(dueOn)).To(BeTrue())
+	})
+})
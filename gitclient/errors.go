@@ -0,0 +1,118 @@
+package gitclient
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// APIError is a non-2xx response from the GitHub API.
+type APIError struct {
+	StatusCode int `json:"-"`
+	// Message is GitHub's explanation of the error.
+	Message string `json:"message"`
+	// DocumentationURL links to the documentation of the failed endpoint.
+	DocumentationURL string `json:"documentation_url,omitempty"`
+	// Errors lists the individual problems of a validation error.
+	Errors []FieldError `json:"errors,omitempty"`
+}
+
+// FieldError is a single problem reported with a 422 validation error.
+type FieldError struct {
+	Resource string `json:"resource,omitempty"`
+	Field    string `json:"field,omitempty"`
+	Code     string `json:"code,omitempty"`
+	Message  string `json:"message,omitempty"`
+}
+
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("%d %s", e.StatusCode, http.StatusText(e.StatusCode))
+	if e.Message != "" && e.Message != http.StatusText(e.StatusCode) {
+		msg += ": " + e.Message
+	}
+	if len(e.Errors) > 0 {
+		details := make([]string, 0, len(e.Errors))
+		for _, fieldErr := range e.Errors {
+			details = append(details, fieldErr.String())
+		}
+		msg += " (" + strings.Join(details, "; ") + ")"
+	}
+	return msg
+}
+
+func (e FieldError) String() string {
+	if e.Message != "" {
+		return e.Message
+	}
+	return fmt.Sprintf("%s.%s %s", e.Resource, e.Field, e.Code)
+}
+
+// newAPIError builds an APIError from an error response body. Bodies that are
+// not GitHub's JSON error document fall back to the HTTP status text.
+func newAPIError(statusCode int, body []byte) *APIError {
+	apiErr := &APIError{StatusCode: statusCode}
+	if err := json.Unmarshal(body, apiErr); err != nil || apiErr.Message == "" {
+		apiErr.Message = http.StatusText(statusCode)
+	}
+	return apiErr
+}
+
+// statusCode returns the HTTP status code of an APIError wrapped in err, or 0.
+func statusCode(err error) int {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode
+	}
+	return 0
+}
+
+// IsNotFound reports whether err means the repository or issue does not exist
+// or is not visible to the credentials in use.
+func IsNotFound(err error) bool {
+	return statusCode(err) == http.StatusNotFound
+}
+
+// IsUnauthorized reports whether err means the credentials were rejected.
+func IsUnauthorized(err error) bool {
+	return statusCode(err) == http.StatusUnauthorized
+}
+
+// IsForbidden reports whether err means the credentials lack permission. Rate
+// limit errors are not reported as forbidden.
+func IsForbidden(err error) bool {
+	var rateLimitErr *RateLimitError
+	return statusCode(err) == http.StatusForbidden && !errors.As(err, &rateLimitErr)
+}
+
+// IsValidation reports whether GitHub rejected the request content.
+func IsValidation(err error) bool {
+	return statusCode(err) == http.StatusUnprocessableEntity
+}
+
+// IsAlreadyExists reports whether GitHub rejected the request because the
+// resource it creates exists already.
+func IsAlreadyExists(err error) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnprocessableEntity {
+		return false
+	}
+	for _, fieldErr := range apiErr.Errors {
+		if fieldErr.Code == "already_exists" {
+			return true
+		}
+	}
+	return false
+}
+
+// IsRetryable reports whether the failed request may succeed if retried
+// unchanged.
+func IsRetryable(err error) bool {
+	var rateLimitErr *RateLimitError
+	if errors.As(err, &rateLimitErr) {
+		return true
+	}
+	code := statusCode(err)
+	return code == http.StatusRequestTimeout || code == http.StatusTooManyRequests || code >= 500
+}
@@ -0,0 +1,103 @@
+package gitclient_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/zszabo-rh/issues-operator/gitclient"
+)
+
+var _ = Describe("API errors", func() {
+
+	var (
+		server  *httptest.Server
+		client  *gitclient.GitClient
+		handler http.HandlerFunc
+	)
+
+	BeforeEach(func() {
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			handler(w, r)
+		}))
+
+		var err error
+		client, err = gitclient.NewGitClient("git@github.com:myrepo/myuser.git",
+			gitclient.WithBaseURL(server.URL), gitclient.WithToken("abc123"))
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	Context("when AddIssue fails validation", func() {
+		It("should return the validation errors instead of an empty issue", func() {
+			handler = func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusUnprocessableEntity)
+				fmt.Fprint(w, `{"message":"Validation Failed","documentation_url":"https://docs.github.com/rest/issues",`+
+					`"errors":[{"resource":"Issue","field":"title","code":"missing_field"}]}`)
+			}
+
+			gitissue, err := client.AddIssue(ctx, "", "description")
+			Expect(gitissue).To(Equal(gitclient.GitIssue{}))
+			Expect(gitclient.IsValidation(err)).To(BeTrue())
+			Expect(gitclient.IsRetryable(err)).To(BeFalse())
+
+			apiErr, ok := err.(*gitclient.APIError)
+			Expect(ok).To(BeTrue())
+			Expect(apiErr.DocumentationURL).To(Equal("https://docs.github.com/rest/issues"))
+			Expect(apiErr.Errors).To(ConsistOf(gitclient.FieldError{Resource: "Issue", Field: "title", Code: "missing_field"}))
+			Expect(err.Error()).To(Equal("422 Unprocessable Entity: Validation Failed (Issue.title missing_field)"))
+		})
+	})
+
+	Context("when the response is not a JSON error document", func() {
+		It("should fall back to the status text", func() {
+			handler = func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusBadGateway)
+				fmt.Fprint(w, "<html>bad gateway</html>")
+			}
+
+			_, err := client.GetIssue(ctx, 1)
+			Expect(err).To(MatchError("502 Bad Gateway"))
+			Expect(gitclient.IsRetryable(err)).To(BeTrue())
+		})
+	})
+
+	DescribeTable("classifying status codes",
+		func(status int, notFound, unauthorized, forbidden, retryable bool) {
+			handler = func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(status)
+				fmt.Fprint(w, `{"message":"failed"}`)
+			}
+
+			_, err := client.GetIssue(ctx, 1)
+			Expect(gitclient.IsNotFound(err)).To(Equal(notFound))
+			Expect(gitclient.IsUnauthorized(err)).To(Equal(unauthorized))
+			Expect(gitclient.IsForbidden(err)).To(Equal(forbidden))
+			Expect(gitclient.IsRetryable(err)).To(Equal(retryable))
+		},
+		Entry("404", http.StatusNotFound, true, false, false, false),
+		Entry("401", http.StatusUnauthorized, false, true, false, false),
+		Entry("403", http.StatusForbidden, false, false, true, false),
+		Entry("500", http.StatusInternalServerError, false, false, false, true),
+	)
+
+	Context("when rate limited", func() {
+		It("should be retryable but not forbidden", func() {
+			handler = func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("X-RateLimit-Remaining", "0")
+				w.WriteHeader(http.StatusForbidden)
+				fmt.Fprint(w, `{"message":"API rate limit exceeded"}`)
+			}
+
+			_, err := client.GetIssue(ctx, 1)
+			Expect(gitclient.IsForbidden(err)).To(BeFalse())
+			Expect(gitclient.IsRetryable(err)).To(BeTrue())
+		})
+	})
+})
@@ -2,26 +2,102 @@ package gitclient
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"strings"
+	"time"
 
 	"github.com/kelseyhightower/envconfig"
 )
 
 type GitClient struct {
-	repo  string
-	token string
+	repo       string
+	tokens     TokenSource
+	credential string
+	timeout    time.Duration
+	httpClient *http.Client
 }
 
+// DefaultBaseURL is the REST API endpoint of github.com.
+const DefaultBaseURL = "https://api.github.com/"
+
+// DefaultTimeout bounds every request made by a GitClient unless WithTimeout
+// says otherwise.
+const DefaultTimeout = 30 * time.Second
+
 type GitIssue struct {
-	Title       string `json:"title"`
-	Description string `json:"body"`
-	Status      string `json:"state"`
-	Id          int    `json:"number"`
-	LastUpdated string `json:"updated_at"`
+	Title       string        `json:"title"`
+	Description string        `json:"body"`
+	Status      string        `json:"state"`
+	Id          int           `json:"number"`
+	LastUpdated string        `json:"updated_at"`
+	Labels      []GitLabel    `json:"labels,omitempty"`
+	StateReason string        `json:"state_reason,omitempty"`
+	Locked      bool          `json:"locked,omitempty"`
+	Comments    int           `json:"comments,omitempty"`
+	Assignees   []GitUser     `json:"assignees,omitempty"`
+	Milestone   *GitMilestone `json:"milestone,omitempty"`
+	HTMLURL     string        `json:"html_url,omitempty"`
+	User        *GitUser      `json:"user,omitempty"`
+	CreatedAt   string        `json:"created_at,omitempty"`
+	ClosedAt    string        `json:"closed_at,omitempty"`
+	ClosedBy    *GitUser      `json:"closed_by,omitempty"`
+}
+
+// GitMilestone is a milestone of a repository.
+type GitMilestone struct {
+	Number int        `json:"number,omitempty"`
+	Title  string     `json:"title"`
+	State  string     `json:"state,omitempty"`
+	DueOn  *time.Time `json:"due_on,omitempty"`
+}
+
+// GitUser is a GitHub user.
+type GitUser struct {
+	Login string `json:"login"`
+}
+
+// GitComment is a comment on an issue.
+type GitComment struct {
+	Id   int64  `json:"id"`
+	Body string `json:"body"`
+}
+
+// Issue states and the reasons an issue may be closed or reopened with.
+const (
+	StateOpen   = "open"
+	StateClosed = "closed"
+
+	StateReasonCompleted  = "completed"
+	StateReasonNotPlanned = "not_planned"
+	StateReasonDuplicate  = "duplicate"
+	StateReasonReopened   = "reopened"
+)
+
+// IssueEdit lists the changes to make to an issue. Empty fields are left
+// unchanged.
+type IssueEdit struct {
+	Title       string `json:"title,omitempty"`
+	Description string `json:"body,omitempty"`
+	State       string `json:"state,omitempty"`
+	StateReason string `json:"state_reason,omitempty"`
+	// Labels replaces the labels of the issue unless it is nil.
+	Labels *[]string `json:"labels,omitempty"`
+	// Assignees replaces the assignees of the issue unless it is nil.
+	Assignees *[]string `json:"assignees,omitempty"`
+	// Milestone is the number of the milestone to add the issue to.
+	Milestone int `json:"milestone,omitempty"`
+}
+
+type GitLabel struct {
+	Name        string `json:"name"`
+	Color       string `json:"color,omitempty"`
+	Description string `json:"description,omitempty"`
 }
 
 type Env struct {
@@ -37,130 +113,319 @@ func GetToken() (string, error) {
 	return env.GitToken, nil
 }
 
+// BuildUrl returns the issues endpoint of repo on DefaultBaseURL.
 func BuildUrl(repo string) (string, error) {
-	split1 := strings.Split(repo, ":")
-	if len(split1) != 2 {
-		return "", fmt.Errorf("invalid repo format")
-	}
-	split2 := strings.Split(split1[1], ".")
-	if len(split2) != 2 {
-		return "", fmt.Errorf("invalid repo format")
+	ref, err := ParseRepo(repo)
+	if err != nil {
+		return "", err
 	}
-	url := "https://api.github.com/repos/" + split2[0] + "/issues"
-	return url, nil
+	return issuesURL(DefaultBaseURL, ref), nil
 }
 
-func NewGitClient(repo string) (*GitClient, error) {
-	httprepo, err := BuildUrl(repo)
-	if err != nil {
-		return nil, err
-	}
-	token, err := GetToken()
+// issuesURL returns the issues endpoint of ref on the API at baseURL.
+func issuesURL(baseURL string, ref RepoRef) string {
+	return strings.TrimSuffix(baseURL, "/") + "/repos/" + url.PathEscape(ref.Owner) + "/" + url.PathEscape(ref.Name) + "/issues"
+}
+
+// NewGitClient returns a client for the issues of repo, which may be given in
+// any form ParseRepo accepts.
+func NewGitClient(repo string, opts ...Option) (*GitClient, error) {
+	ref, err := ParseRepo(repo)
 	if err != nil {
 		return nil, err
 	}
-	g := GitClient{httprepo, token}
-	return &g, nil
+	return NewRepoClient(ref, opts...)
 }
 
-func (g *GitClient) GetIssues() ([]GitIssue, error) {
-	client := &http.Client{}
-	req, err := http.NewRequest("GET", g.repo, nil)
-	if err != nil {
-		return nil, err
+// NewRepoClient returns a client for the issues of ref.
+func NewRepoClient(ref RepoRef, opts ...Option) (*GitClient, error) {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
 	}
-	req.Header.Set("Authorization", "token "+g.token)
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, err
+
+	hosts := o.hosts
+	if hosts == nil {
+		hosts = defaultHosts
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode > 299 {
-		return nil, fmt.Errorf("%v", http.StatusText(resp.StatusCode))
+	baseURL, tokens := o.baseURL, o.tokens
+	if o.app != nil && o.app.Host() == ref.Host {
+		if baseURL == "" {
+			baseURL = o.app.apiURL
+		}
+		if tokens == nil {
+			tokens = o.app.TokenSource(ref.Owner)
+		}
+	}
+	if baseURL == "" {
+		hostCfg, err := hosts.Lookup(ref.Host)
+		if err != nil {
+			return nil, err
+		}
+		baseURL = hostCfg.APIURL
+	}
+	if tokens == nil {
+		var err error
+		tokens, err = hosts.TokenSource(ref.Host)
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
+	timeout := DefaultTimeout
+	if o.timeout > 0 {
+		timeout = o.timeout
+	}
+	httpClient := defaultHTTPClient
+	if o.httpClient != nil {
+		httpClient = o.httpClient
 	}
-	var gitissues []GitIssue
-	err = json.Unmarshal([]byte(body), &gitissues)
+	g := GitClient{
+		repo:       issuesURL(baseURL, ref),
+		tokens:     tokens,
+		credential: o.credential,
+		timeout:    timeout,
+		httpClient: httpClient,
+	}
+	return &g, nil
+}
+
+// GetIssues returns every issue matching opts, following pagination to the
+// last page.
+func (g *GitClient) GetIssues(ctx context.Context, opts ListOptions) ([]GitIssue, error) {
+	gitissues := []GitIssue{}
+	err := g.IterateIssues(ctx, opts, func(gitissue GitIssue) bool {
+		gitissues = append(gitissues, gitissue)
+		return true
+	})
 	if err != nil {
 		return nil, err
 	}
 	return gitissues, nil
 }
 
-func (g *GitClient) AddIssue(title string, desc string) (GitIssue, error) {
+func (g *GitClient) GetIssue(ctx context.Context, Id int) (GitIssue, error) {
+	var gitissue GitIssue
+	err := g.send(ctx, "GET", g.repo+"/"+fmt.Sprint(Id), nil, &gitissue)
+	if err != nil {
+		return GitIssue{}, err
+	}
+	return gitissue, nil
+}
+
+func (g *GitClient) AddIssue(ctx context.Context, title string, desc string) (GitIssue, error) {
 	gitissue := GitIssue{
 		Title:       title,
 		Description: desc}
 
-	gitissueJson, err := json.Marshal(gitissue)
+	err := g.send(ctx, "POST", g.repo, gitissue, &gitissue)
 	if err != nil {
 		return GitIssue{}, err
 	}
+	return gitissue, nil
+}
+
+func (g *GitClient) UpdateIssue(ctx context.Context, Id int, title string, desc string) (GitIssue, error) {
+	return g.EditIssue(ctx, Id, IssueEdit{Title: title, Description: desc})
+}
 
-	client := &http.Client{}
-	req, err := http.NewRequest("POST", g.repo, bytes.NewBuffer(gitissueJson))
+func (g *GitClient) CloseIssue(ctx context.Context, Id int) (GitIssue, error) {
+	return g.EditIssue(ctx, Id, IssueEdit{State: StateClosed})
+}
+
+// EditIssue applies edit to the issue Id in a single request, so that its
+// title, body and state change together.
+func (g *GitClient) EditIssue(ctx context.Context, Id int, edit IssueEdit) (GitIssue, error) {
+	var gitissue GitIssue
+	err := g.send(ctx, "PATCH", g.repo+"/"+fmt.Sprint(Id), edit, &gitissue)
 	if err != nil {
 		return GitIssue{}, err
 	}
+	return gitissue, nil
+}
 
-	req.Header.Add("Accept", "application/json")
-	req.Header.Add("Authorization", "Bearer "+g.token)
+// AddComment posts a comment with the given body on the issue Id.
+func (g *GitClient) AddComment(ctx context.Context, Id int, body string) (GitComment, error) {
+	comment := GitComment{Body: body}
+	err := g.send(ctx, "POST", g.repo+"/"+fmt.Sprint(Id)+"/comments", comment, &comment)
+	if err != nil {
+		return GitComment{}, err
+	}
+	return comment, nil
+}
+
+// LockIssue locks the conversation on the issue Id. reason may be empty or
+// one of off-topic, too heated, resolved and spam.
+func (g *GitClient) LockIssue(ctx context.Context, Id int, reason string) error {
+	var payload any
+	if reason != "" {
+		payload = map[string]string{"lock_reason": reason}
+	}
+	return g.send(ctx, "PUT", g.repo+"/"+fmt.Sprint(Id)+"/lock", payload, nil)
+}
 
-	resp, err := client.Do(req)
+// IsAssignable reports whether issues in the repository can be assigned to
+// the user with the given login.
+func (g *GitClient) IsAssignable(ctx context.Context, login string) (bool, error) {
+	err := g.send(ctx, "GET", g.repoURL()+"/assignees/"+url.PathEscape(login), nil, nil)
+	if IsNotFound(err) {
+		return false, nil
+	}
 	if err != nil {
-		return GitIssue{}, err
+		return false, err
 	}
-	defer resp.Body.Close()
+	return true, nil
+}
 
-	body, err := io.ReadAll(resp.Body)
+// GetLabel returns the repository label called name.
+func (g *GitClient) GetLabel(ctx context.Context, name string) (GitLabel, error) {
+	var label GitLabel
+	err := g.send(ctx, "GET", g.labelsURL()+"/"+url.PathEscape(name), nil, &label)
 	if err != nil {
-		return GitIssue{}, err
+		return GitLabel{}, err
 	}
+	return label, nil
+}
 
-	err = json.Unmarshal([]byte(body), &gitissue)
-	return gitissue, err
+// CreateLabel adds label to the repository.
+func (g *GitClient) CreateLabel(ctx context.Context, label GitLabel) (GitLabel, error) {
+	err := g.send(ctx, "POST", g.labelsURL(), label, &label)
+	if err != nil {
+		return GitLabel{}, err
+	}
+	return label, nil
 }
 
-func (g *GitClient) UpdateIssue(Id int, title string, desc string) (GitIssue, error) {
-	gitissue := GitIssue{
-		Title:       title,
-		Description: desc}
+// ListMilestones returns all milestones of the repository, open or closed.
+func (g *GitClient) ListMilestones(ctx context.Context) ([]GitMilestone, error) {
+	var milestones []GitMilestone
+	next := g.repoURL() + "/milestones?state=all&per_page=100"
+	for next != "" {
+		var page []GitMilestone
+		header, err := g.do(ctx, "GET", next, nil, &page)
+		if err != nil {
+			return nil, err
+		}
+		milestones = append(milestones, page...)
+		next = nextPageURL(header.Get("Link"))
+	}
+	return milestones, nil
+}
 
-	gitissueJson, err := json.Marshal(gitissue)
+// CreateMilestone adds milestone to the repository.
+func (g *GitClient) CreateMilestone(ctx context.Context, milestone GitMilestone) (GitMilestone, error) {
+	err := g.send(ctx, "POST", g.repoURL()+"/milestones", milestone, &milestone)
 	if err != nil {
-		return GitIssue{}, err
+		return GitMilestone{}, err
 	}
+	return milestone, nil
+}
 
-	client := &http.Client{}
-	req, err := http.NewRequest("PATCH", g.repo+"/"+fmt.Sprint(Id), bytes.NewBuffer(gitissueJson))
+// repoURL returns the API URL of the repository.
+func (g *GitClient) repoURL() string {
+	return strings.TrimSuffix(g.repo, "/issues")
+}
 
+// labelsURL returns the labels endpoint of the repository.
+func (g *GitClient) labelsURL() string {
+	return g.repoURL() + "/labels"
+}
+
+// send performs a single authenticated request against the GitHub API. The
+// payload, if any, is sent as JSON and a successful response is decoded into
+// out, unless out is nil.
+func (g *GitClient) send(ctx context.Context, method string, url string, payload any, out any) error {
+	_, err := g.do(ctx, method, url, payload, out)
+	return err
+}
+
+// do is like send but also returns the response headers. The request is
+// cancelled when ctx is done or the client timeout expires, whichever comes
+// first.
+func (g *GitClient) do(ctx context.Context, method string, url string, payload any, out any) (http.Header, error) {
+	ctx, cancel := context.WithTimeout(ctx, g.timeout)
+	defer cancel()
+
+	token, err := g.tokens.Token(ctx)
 	if err != nil {
-		return GitIssue{}, err
+		return nil, err
+	}
+	header, err := doRequest(ctx, g.httpClient, method, url, token, payload, out)
+	if rateLimit, ok := parseRateLimit(header); ok {
+		observeRateLimitRemaining(url, g.credentialName(token), rateLimit)
+	}
+	if observer, ok := g.tokens.(rateLimitObserver); ok {
+		var rateLimitErr *RateLimitError
+		if errors.As(err, &rateLimitErr) {
+			observer.observeRateLimitError(token, rateLimitErr)
+		} else if rateLimit, ok := parseRateLimit(header); ok {
+			observer.observeRateLimit(token, rateLimit)
+		}
+	}
+	if IsUnauthorized(err) {
+		if inv, ok := g.tokens.(invalidator); ok {
+			inv.invalidate(token)
+		}
+	}
+	if err != nil {
+		return nil, err
 	}
+	return header, nil
+}
 
-	req.Header.Add("Accept", "application/json")
-	req.Header.Add("Authorization", "Bearer "+g.token)
+// credentialName names the credential token belongs to in metrics.
+func (g *GitClient) credentialName(token string) string {
+	if g.credential != "" {
+		return g.credential
+	}
+	return credentialNameOf(g.tokens, token)
+}
 
-	resp, err := client.Do(req)
+// doRequest sends a request authorized with token and decodes a successful
+// JSON response into out. Error responses are returned as *APIError or
+// *RateLimitError, along with their headers.
+func doRequest(ctx context.Context, httpClient *http.Client, method string, url string, token string, payload any, out any) (http.Header, error) {
+	var reqBody io.Reader
+	if payload != nil {
+		payloadJson, err := json.Marshal(payload)
+		if err != nil {
+			return nil, err
+		}
+		reqBody = bytes.NewBuffer(payloadJson)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, reqBody)
 	if err != nil {
-		return GitIssue{}, err
+		return nil, err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode > 299 {
-		return GitIssue{}, fmt.Errorf("%v", http.StatusText(resp.StatusCode))
+	req.Header.Add("Accept", "application/json")
+	req.Header.Add("Authorization", "Bearer "+token)
+
+	start := time.Now()
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		observeRequest(method, req.URL, 0, time.Since(start))
+		return nil, err
 	}
+	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
+	observeRequest(method, req.URL, resp.StatusCode, time.Since(start))
 	if err != nil {
-		return GitIssue{}, err
+		return nil, err
 	}
 
-	err = json.Unmarshal([]byte(body), &gitissue)
-	return gitissue, err
+	if resp.StatusCode > 299 {
+		apiErr := newAPIError(resp.StatusCode, body)
+		if rateLimitErr := checkRateLimit(resp, apiErr); rateLimitErr != nil {
+			return resp.Header, rateLimitErr
+		}
+		return resp.Header, apiErr
+	}
+	if out == nil || len(body) == 0 {
+		return resp.Header, nil
+	}
+	return resp.Header, json.Unmarshal(body, out)
 }
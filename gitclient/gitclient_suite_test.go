@@ -1,12 +1,16 @@
 package gitclient_test
 
 import (
+	"context"
 	"testing"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 )
 
+// ctx is the context used for calls to the client under test.
+var ctx = context.Background()
+
 func TestGitclient(t *testing.T) {
 	RegisterFailHandler(Fail)
 	RunSpecs(t, "Gitclient Suite")
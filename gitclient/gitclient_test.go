@@ -78,7 +78,7 @@ var _ = Describe("Gitclient", func() {
 		It("should return the issue list", func() {
 			client, err := gitclient.NewGitClient("git@github.com:zszabo-rh/issues-operator.git")
 			Expect(err).ToNot(HaveOccurred())
-			gitissues, err := client.GetIssues()
+			gitissues, err := client.GetIssues(ctx, gitclient.ListOptions{})
 			Expect(err).ToNot(HaveOccurred())
 			Expect(len(gitissues)).To(BeNumerically(">", 0))
 		})
@@ -88,8 +88,8 @@ var _ = Describe("Gitclient", func() {
 		It("should return an error", func() {
 			client, err := gitclient.NewGitClient("git@github.com:zszabo/issues-operator.git")
 			Expect(err).ToNot(HaveOccurred())
-			gitissues, err := client.GetIssues()
-			Expect(err).To(Equal(fmt.Errorf("%v", "Not Found")))
+			gitissues, err := client.GetIssues(ctx, gitclient.ListOptions{})
+			Expect(gitclient.IsNotFound(err)).To(BeTrue())
 			Expect(gitissues).To(BeNil())
 		})
 	})
@@ -111,8 +111,8 @@ var _ = Describe("Gitclient", func() {
 		It("should return an error", func() {
 			client, err := gitclient.NewGitClient("git@github.com:zszabo-rh/issues-operator.git")
 			Expect(err).ToNot(HaveOccurred())
-			gitissues, err := client.GetIssues()
-			Expect(err).To(Equal(fmt.Errorf("%v", "Unauthorized")))
+			gitissues, err := client.GetIssues(ctx, gitclient.ListOptions{})
+			Expect(gitclient.IsUnauthorized(err)).To(BeTrue())
 			Expect(gitissues).To(BeNil())
 		})
 	})
@@ -125,7 +125,7 @@ var _ = Describe("Gitclient", func() {
 			client, err := gitclient.NewGitClient("git@github.com:zszabo-rh/issues-operator.git")
 			Expect(err).ToNot(HaveOccurred())
 			issueTitle := fmt.Sprintf("Generated_test_issue_%v", time.Now().Format("2006-01-02T15:04:05Z"))
-			gitissue, err := client.AddIssue(issueTitle, "description")
+			gitissue, err := client.AddIssue(ctx, issueTitle, "description")
 			issueId = gitissue.Id
 			Expect(err).ToNot(HaveOccurred())
 			Expect(gitissue.Title).To(Equal(issueTitle))
@@ -137,7 +137,7 @@ var _ = Describe("Gitclient", func() {
 			client, err := gitclient.NewGitClient("git@github.com:zszabo-rh/issues-operator.git")
 			Expect(err).ToNot(HaveOccurred())
 			issueTitle := fmt.Sprintf("Generated_test_issue_%v", time.Now().Format("2006-01-02T15:04:05Z"))
-			gitissue, err := client.UpdateIssue(issueId, issueTitle, "new description")
+			gitissue, err := client.UpdateIssue(ctx, issueId, issueTitle, "new description")
 			Expect(err).ToNot(HaveOccurred())
 			Expect(gitissue.Title).To(Equal(issueTitle))
 			Expect(gitissue.Description).To(Equal("new description"))
@@ -149,8 +149,8 @@ var _ = Describe("Gitclient", func() {
 			client, err := gitclient.NewGitClient("git@github.com:zszabo-rh/issues-operator.git")
 			Expect(err).ToNot(HaveOccurred())
 			issueTitle := fmt.Sprintf("Generated_test_issue_%v", time.Now().Format("2006-01-02T15:04:05Z"))
-			gitissue, err := client.UpdateIssue(999, issueTitle, "new description")
-			Expect(err).To(Equal(fmt.Errorf("%v", "Not Found")))
+			gitissue, err := client.UpdateIssue(ctx, 999, issueTitle, "new description")
+			Expect(gitclient.IsNotFound(err)).To(BeTrue())
 			Expect(gitissue.Title).To(Equal(""))
 		})
 	})
@@ -0,0 +1,236 @@
+package gitclient
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// DefaultTokenEnv is the environment variable holding the token for
+// DefaultHost.
+const DefaultTokenEnv = "GITTOKEN"
+
+var nonAlphanumeric = regexp.MustCompile(`[^A-Z0-9]+`)
+
+// defaultHosts is used by GitClients created without WithHosts.
+var defaultHosts = mustNewHosts()
+
+// HostConfig describes how to reach the API of a GitHub host and which
+// credentials to use there.
+type HostConfig struct {
+	// Host is the host repositories are cloned from, e.g. github.example.com.
+	Host string `json:"host"`
+	// APIURL is the REST API endpoint of the host. It defaults to
+	// DefaultBaseURL for github.com and to https://<host>/api/v3, the GitHub
+	// Enterprise Server layout, otherwise.
+	APIURL string `json:"apiURL,omitempty"`
+	// TokenEnv is the environment variable holding the token for the host. It
+	// defaults to GITTOKEN for github.com and to GITTOKEN_<HOST> otherwise,
+	// e.g. GITTOKEN_GITHUB_EXAMPLE_COM.
+	TokenEnv string `json:"tokenEnv,omitempty"`
+	// TokenFile is a file holding the token for the host. It takes precedence
+	// over TokenEnv and is read again whenever it changes.
+	TokenFile string `json:"tokenFile,omitempty"`
+	// TokenExec is a credential plugin printing the token for the host. It
+	// takes precedence over TokenFile and TokenEnv.
+	TokenExec *ExecConfig `json:"tokenExec,omitempty"`
+	// TokenPool lists several tokens to spread requests to the host over.
+	// Each request uses the token with the most remaining quota. It takes
+	// precedence over the other token settings.
+	TokenPool []TokenConfig `json:"tokenPool,omitempty"`
+}
+
+// TokenConfig locates one token of a pool. Exactly one field must be set.
+type TokenConfig struct {
+	// Env is an environment variable holding the token.
+	Env string `json:"env,omitempty"`
+	// File is a file holding the token.
+	File string `json:"file,omitempty"`
+	// Exec is a credential plugin printing the token.
+	Exec *ExecConfig `json:"exec,omitempty"`
+}
+
+// hostsFile is the format read by LoadHostsFile.
+type hostsFile struct {
+	Hosts []HostConfig `json:"hosts"`
+}
+
+// Hosts maps GitHub hosts to their configuration. github.com is always
+// known; other hosts must be added explicitly, so that tokens are never sent
+// to a host nobody configured.
+type Hosts struct {
+	hosts  map[string]HostConfig
+	tokens map[string]TokenSource
+}
+
+// NewHosts returns Hosts knowing github.com and the given hosts.
+func NewHosts(configs ...HostConfig) (*Hosts, error) {
+	h := &Hosts{hosts: map[string]HostConfig{}, tokens: map[string]TokenSource{}}
+	if err := h.Add(HostConfig{Host: DefaultHost}); err != nil {
+		return nil, err
+	}
+	for _, cfg := range configs {
+		if err := h.Add(cfg); err != nil {
+			return nil, err
+		}
+	}
+	return h, nil
+}
+
+func mustNewHosts() *Hosts {
+	h, err := NewHosts()
+	if err != nil {
+		panic(err)
+	}
+	return h
+}
+
+// LoadHostsFile reads host configurations from a YAML file of the form
+//
+//	hosts:
+//	- host: github.example.com
+//	  apiURL: https://github.example.com/api/v3
+//	  tokenFile: /etc/issues-operator/github.example.com/token
+//	- host: github.other.example.com
+//	  tokenExec:
+//	    command: /usr/local/bin/github-token
+//	    args: ["--host", "github.other.example.com"]
+//	- host: github.com
+//	  tokenPool:
+//	  - env: GITTOKEN
+//	  - file: /etc/issues-operator/github.com/second-token
+func LoadHostsFile(path string) (*Hosts, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var file hostsFile
+	if err := yaml.UnmarshalStrict(data, &file); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return NewHosts(file.Hosts...)
+}
+
+// Add registers cfg, replacing any previous configuration of the same host.
+func (h *Hosts) Add(cfg HostConfig) error {
+	cfg.Host = strings.ToLower(strings.TrimSpace(cfg.Host))
+	if cfg.Host == "" {
+		return fmt.Errorf("host configuration without a host")
+	}
+
+	if cfg.APIURL == "" {
+		cfg.APIURL = defaultAPIURL(cfg.Host)
+	}
+	u, err := url.Parse(cfg.APIURL)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return fmt.Errorf("invalid API URL %q for host %s", cfg.APIURL, cfg.Host)
+	}
+
+	if cfg.TokenEnv == "" {
+		if cfg.Host == DefaultHost {
+			cfg.TokenEnv = DefaultTokenEnv
+		} else {
+			cfg.TokenEnv = DefaultTokenEnv + "_" + strings.Trim(nonAlphanumeric.ReplaceAllString(strings.ToUpper(cfg.Host), "_"), "_")
+		}
+	}
+
+	if cfg.TokenExec != nil && cfg.TokenExec.Command == "" {
+		return fmt.Errorf("tokenExec without a command for host %s", cfg.Host)
+	}
+	for i, token := range cfg.TokenPool {
+		if err := token.validate(); err != nil {
+			return fmt.Errorf("tokenPool[%d] for host %s: %w", i, cfg.Host, err)
+		}
+	}
+
+	h.hosts[cfg.Host] = cfg
+	h.tokens[cfg.Host] = cfg.tokenSource()
+	return nil
+}
+
+// defaultAPIURL returns the REST API endpoint of host when none is
+// configured.
+func defaultAPIURL(host string) string {
+	if host == DefaultHost {
+		return DefaultBaseURL
+	}
+	return "https://" + host + "/api/v3"
+}
+
+// Lookup returns the configuration of host.
+func (h *Hosts) Lookup(host string) (HostConfig, error) {
+	cfg, ok := h.hosts[strings.ToLower(host)]
+	if !ok {
+		return HostConfig{}, fmt.Errorf("GitHub host %s is not configured", host)
+	}
+	return cfg, nil
+}
+
+// TokenSource returns the source of tokens for host. It is shared by all
+// clients for the host, so that tokens cached by file and exec sources are
+// reused between them.
+func (h *Hosts) TokenSource(host string) (TokenSource, error) {
+	tokens, ok := h.tokens[strings.ToLower(host)]
+	if !ok {
+		return nil, fmt.Errorf("GitHub host %s is not configured", host)
+	}
+	return tokens, nil
+}
+
+// Token reads the token for the host from TokenExec, TokenFile or TokenEnv.
+func (cfg HostConfig) Token() (string, error) {
+	token, err := cfg.tokenSource().Token(context.Background())
+	if err != nil {
+		return "", fmt.Errorf("token for %s: %w", cfg.Host, err)
+	}
+	return token, nil
+}
+
+func (cfg HostConfig) tokenSource() TokenSource {
+	switch {
+	case len(cfg.TokenPool) > 0:
+		sources := make([]TokenSource, 0, len(cfg.TokenPool))
+		for _, token := range cfg.TokenPool {
+			sources = append(sources, token.tokenSource())
+		}
+		return NewTokenPool(sources...)
+	case cfg.TokenExec != nil:
+		return ExecToken(*cfg.TokenExec)
+	case cfg.TokenFile != "":
+		return FileToken(cfg.TokenFile)
+	default:
+		return EnvToken(cfg.TokenEnv)
+	}
+}
+
+func (cfg TokenConfig) validate() error {
+	set := 0
+	for _, ok := range []bool{cfg.Env != "", cfg.File != "", cfg.Exec != nil} {
+		if ok {
+			set++
+		}
+	}
+	if set != 1 {
+		return fmt.Errorf("exactly one of env, file and exec must be set")
+	}
+	if cfg.Exec != nil && cfg.Exec.Command == "" {
+		return fmt.Errorf("exec without a command")
+	}
+	return nil
+}
+
+func (cfg TokenConfig) tokenSource() TokenSource {
+	switch {
+	case cfg.Exec != nil:
+		return ExecToken(*cfg.Exec)
+	case cfg.File != "":
+		return FileToken(cfg.File)
+	default:
+		return EnvToken(cfg.Env)
+	}
+}
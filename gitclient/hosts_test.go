@@ -0,0 +1,126 @@
+package gitclient_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/zszabo-rh/issues-operator/gitclient"
+)
+
+var _ = Describe("Hosts", func() {
+
+	Context("when no host is configured", func() {
+		It("should only know github.com", func() {
+			hosts, err := gitclient.NewHosts()
+			Expect(err).ToNot(HaveOccurred())
+
+			cfg, err := hosts.Lookup("GitHub.com")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(cfg.APIURL).To(Equal(gitclient.DefaultBaseURL))
+			Expect(cfg.TokenEnv).To(Equal("GITTOKEN"))
+
+			_, err = hosts.Lookup("github.example.com")
+			Expect(err).To(MatchError("GitHub host github.example.com is not configured"))
+		})
+
+		It("should not send the github.com token to other hosts", func() {
+			_, err := gitclient.NewGitClient("git@github.example.com:myuser/myrepo.git")
+			Expect(err).To(MatchError(ContainSubstring("not configured")))
+		})
+	})
+
+	Context("when a GitHub Enterprise Server host is added", func() {
+		It("should default the API URL and token variable from the host", func() {
+			hosts, err := gitclient.NewHosts(gitclient.HostConfig{Host: "github.example.com"})
+			Expect(err).ToNot(HaveOccurred())
+
+			cfg, err := hosts.Lookup("github.example.com")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(cfg.APIURL).To(Equal("https://github.example.com/api/v3"))
+			Expect(cfg.TokenEnv).To(Equal("GITTOKEN_GITHUB_EXAMPLE_COM"))
+		})
+
+		It("should reject an invalid API URL", func() {
+			_, err := gitclient.NewHosts(gitclient.HostConfig{Host: "github.example.com", APIURL: "github.example.com/api"})
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("should send requests to the host API with the host token", func() {
+			var authorization string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				Expect(r.URL.Path).To(Equal("/api/v3/repos/myuser/myrepo/issues/1"))
+				authorization = r.Header.Get("Authorization")
+				fmt.Fprint(w, `{"number":1,"title":"issue","state":"open"}`)
+			}))
+			defer server.Close()
+
+			original, ok := os.LookupEnv("GITTOKEN_GITHUB_EXAMPLE_COM")
+			os.Setenv("GITTOKEN_GITHUB_EXAMPLE_COM", "enterprise-token")
+			defer func() {
+				if ok {
+					os.Setenv("GITTOKEN_GITHUB_EXAMPLE_COM", original)
+				} else {
+					os.Unsetenv("GITTOKEN_GITHUB_EXAMPLE_COM")
+				}
+			}()
+
+			hosts, err := gitclient.NewHosts(gitclient.HostConfig{Host: "github.example.com", APIURL: server.URL + "/api/v3"})
+			Expect(err).ToNot(HaveOccurred())
+			client, err := gitclient.NewGitClient("git@github.example.com:myuser/myrepo.git", gitclient.WithHosts(hosts))
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = client.GetIssue(ctx, 1)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(authorization).To(Equal("Bearer enterprise-token"))
+		})
+	})
+
+	Context("when the hosts are loaded from a file", func() {
+		It("should read the host list and per-host token files", func() {
+			dir := GinkgoT().TempDir()
+			tokenFile := filepath.Join(dir, "token")
+			Expect(os.WriteFile(tokenFile, []byte("file-token\n"), 0o600)).To(Succeed())
+			configFile := filepath.Join(dir, "hosts.yaml")
+			Expect(os.WriteFile(configFile, []byte(fmt.Sprintf(`hosts:
+- host: github.example.com
+  apiURL: https://github.example.com/custom/api
+  tokenFile: %s
+`, tokenFile)), 0o600)).To(Succeed())
+
+			hosts, err := gitclient.LoadHostsFile(configFile)
+			Expect(err).ToNot(HaveOccurred())
+			cfg, err := hosts.Lookup("github.example.com")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(cfg.APIURL).To(Equal("https://github.example.com/custom/api"))
+
+			token, err := cfg.Token()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(token).To(Equal("file-token"))
+
+			_, err = hosts.Lookup("github.com")
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("should reject pool entries without exactly one token location", func() {
+			configFile := filepath.Join(GinkgoT().TempDir(), "hosts.yaml")
+			Expect(os.WriteFile(configFile, []byte("hosts:\n- host: github.com\n  tokenPool:\n  - env: GITTOKEN\n    file: /token\n"), 0o600)).To(Succeed())
+
+			_, err := gitclient.LoadHostsFile(configFile)
+			Expect(err).To(MatchError(ContainSubstring("tokenPool[0] for host github.com")))
+		})
+
+		It("should reject unknown fields", func() {
+			configFile := filepath.Join(GinkgoT().TempDir(), "hosts.yaml")
+			Expect(os.WriteFile(configFile, []byte("hosts:\n- host: github.example.com\n  token: secret\n"), 0o600)).To(Succeed())
+
+			_, err := gitclient.LoadHostsFile(configFile)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
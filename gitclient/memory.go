@@ -0,0 +1,375 @@
+package gitclient
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MemoryTracker is an IssueTracker that keeps issues in memory. It is safe for
+// concurrent use and is meant for tests and for running the operator without
+// network access.
+type MemoryTracker struct {
+	mu       sync.Mutex
+	issues   []GitIssue
+	comments map[int][]GitComment
+	labels   map[string]GitLabel
+	// assignable holds the lowercased logins issues can be assigned to.
+	assignable map[string]bool
+	milestones []GitMilestone
+	// htmlURL is the web address issue numbers are appended to, if any.
+	htmlURL string
+}
+
+// NewMemoryTracker returns an empty MemoryTracker.
+func NewMemoryTracker() *MemoryTracker {
+	return &MemoryTracker{}
+}
+
+// NewMemoryTrackerFactory returns a TrackerFactory that hands out one
+// MemoryTracker per repository, so that repeated calls for the same repository
+// see the same issues. Options are ignored.
+func NewMemoryTrackerFactory() TrackerFactory {
+	var mu sync.Mutex
+	trackers := map[RepoRef]*MemoryTracker{}
+
+	return func(ref RepoRef, _ ...Option) (IssueTracker, error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		tracker, ok := trackers[ref]
+		if !ok {
+			tracker = NewMemoryTracker()
+			tracker.htmlURL = "https://" + ref.Host + "/" + ref.FullName() + "/issues/"
+			trackers[ref] = tracker
+		}
+		return tracker, nil
+	}
+}
+
+func (m *MemoryTracker) GetIssues(ctx context.Context, opts ListOptions) ([]GitIssue, error) {
+	gitissues := []GitIssue{}
+	err := m.IterateIssues(ctx, opts, func(gitissue GitIssue) bool {
+		gitissues = append(gitissues, gitissue)
+		return true
+	})
+	return gitissues, err
+}
+
+func (m *MemoryTracker) IterateIssues(ctx context.Context, opts ListOptions, fn func(GitIssue) bool) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	// Work on a snapshot so that fn may call back into the tracker.
+	m.mu.Lock()
+	snapshot := make([]GitIssue, len(m.issues))
+	copy(snapshot, m.issues)
+	m.mu.Unlock()
+
+	for _, gitissue := range snapshot {
+		if !opts.matches(gitissue) {
+			continue
+		}
+		if !fn(gitissue) {
+			return nil
+		}
+	}
+	return nil
+}
+
+func (m *MemoryTracker) GetIssue(ctx context.Context, Id int) (GitIssue, error) {
+	if err := ctx.Err(); err != nil {
+		return GitIssue{}, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	gitissue, err := m.lookup(Id)
+	if err != nil {
+		return GitIssue{}, err
+	}
+	return *gitissue, nil
+}
+
+func (m *MemoryTracker) AddIssue(ctx context.Context, title string, desc string) (GitIssue, error) {
+	if err := ctx.Err(); err != nil {
+		return GitIssue{}, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	gitissue := GitIssue{
+		Title:       title,
+		Description: desc,
+		Status:      "open",
+		Id:          len(m.issues) + 1,
+		LastUpdated: now()}
+	gitissue.CreatedAt = gitissue.LastUpdated
+	if m.htmlURL != "" {
+		gitissue.HTMLURL = m.htmlURL + fmt.Sprint(gitissue.Id)
+	}
+	m.issues = append(m.issues, gitissue)
+	return gitissue, nil
+}
+
+func (m *MemoryTracker) UpdateIssue(ctx context.Context, Id int, title string, desc string) (GitIssue, error) {
+	return m.EditIssue(ctx, Id, IssueEdit{Title: title, Description: desc})
+}
+
+func (m *MemoryTracker) CloseIssue(ctx context.Context, Id int) (GitIssue, error) {
+	return m.EditIssue(ctx, Id, IssueEdit{State: StateClosed})
+}
+
+func (m *MemoryTracker) EditIssue(ctx context.Context, Id int, edit IssueEdit) (GitIssue, error) {
+	if err := ctx.Err(); err != nil {
+		return GitIssue{}, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	gitissue, err := m.lookup(Id)
+	if err != nil {
+		return GitIssue{}, err
+	}
+	if edit.Assignees != nil {
+		for _, login := range *edit.Assignees {
+			if !m.assignable[strings.ToLower(login)] {
+				return GitIssue{}, &APIError{
+					StatusCode: http.StatusUnprocessableEntity,
+					Message:    "Validation Failed",
+					Errors:     []FieldError{{Resource: "Issue", Field: "assignees", Code: "invalid", Message: login + " cannot be assigned"}},
+				}
+			}
+		}
+	}
+	var milestone *GitMilestone
+	if edit.Milestone != 0 {
+		for i := range m.milestones {
+			if m.milestones[i].Number == edit.Milestone {
+				milestone = &m.milestones[i]
+			}
+		}
+		if milestone == nil {
+			return GitIssue{}, &APIError{
+				StatusCode: http.StatusUnprocessableEntity,
+				Message:    "Validation Failed",
+				Errors:     []FieldError{{Resource: "Issue", Field: "milestone", Code: "invalid"}},
+			}
+		}
+	}
+	if edit.Title != "" {
+		gitissue.Title = edit.Title
+	}
+	if edit.Description != "" {
+		gitissue.Description = edit.Description
+	}
+	switch {
+	case edit.State == StateClosed:
+		if gitissue.Status != StateClosed {
+			gitissue.ClosedAt = now()
+		}
+		gitissue.Status = StateClosed
+		gitissue.StateReason = edit.StateReason
+		if gitissue.StateReason == "" {
+			gitissue.StateReason = StateReasonCompleted
+		}
+	case edit.State == StateOpen && gitissue.Status != StateOpen:
+		gitissue.Status = StateOpen
+		gitissue.StateReason = StateReasonReopened
+		gitissue.ClosedAt = ""
+	}
+	if edit.Labels != nil {
+		// Like GitHub, create labels that do not exist yet.
+		gitissue.Labels = []GitLabel{}
+		for _, name := range *edit.Labels {
+			label, ok := m.labels[strings.ToLower(name)]
+			if !ok {
+				label = m.createLabel(GitLabel{Name: name})
+			}
+			gitissue.Labels = append(gitissue.Labels, label)
+		}
+	}
+	if edit.Assignees != nil {
+		gitissue.Assignees = []GitUser{}
+		for _, login := range *edit.Assignees {
+			gitissue.Assignees = append(gitissue.Assignees, GitUser{Login: login})
+		}
+	}
+	if milestone != nil {
+		copied := *milestone
+		gitissue.Milestone = &copied
+	}
+	gitissue.LastUpdated = now()
+	return *gitissue, nil
+}
+
+func (m *MemoryTracker) AddComment(ctx context.Context, Id int, body string) (GitComment, error) {
+	if err := ctx.Err(); err != nil {
+		return GitComment{}, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	gitissue, err := m.lookup(Id)
+	if err != nil {
+		return GitComment{}, err
+	}
+	if m.comments == nil {
+		m.comments = map[int][]GitComment{}
+	}
+	comment := GitComment{Id: int64(len(m.comments[Id]) + 1), Body: body}
+	m.comments[Id] = append(m.comments[Id], comment)
+	gitissue.Comments++
+	gitissue.LastUpdated = now()
+	return comment, nil
+}
+
+func (m *MemoryTracker) LockIssue(ctx context.Context, Id int, reason string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	gitissue, err := m.lookup(Id)
+	if err != nil {
+		return err
+	}
+	gitissue.Locked = true
+	return nil
+}
+
+func (m *MemoryTracker) GetLabel(ctx context.Context, name string) (GitLabel, error) {
+	if err := ctx.Err(); err != nil {
+		return GitLabel{}, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	label, ok := m.labels[strings.ToLower(name)]
+	if !ok {
+		return GitLabel{}, &APIError{StatusCode: http.StatusNotFound, Message: "Not Found"}
+	}
+	return label, nil
+}
+
+func (m *MemoryTracker) CreateLabel(ctx context.Context, label GitLabel) (GitLabel, error) {
+	if err := ctx.Err(); err != nil {
+		return GitLabel{}, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.labels[strings.ToLower(label.Name)]; ok {
+		return GitLabel{}, &APIError{
+			StatusCode: http.StatusUnprocessableEntity,
+			Message:    "Validation Failed",
+			Errors:     []FieldError{{Resource: "Label", Field: "name", Code: "already_exists"}},
+		}
+	}
+	return m.createLabel(label), nil
+}
+
+func (m *MemoryTracker) IsAssignable(ctx context.Context, login string) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.assignable[strings.ToLower(login)], nil
+}
+
+// AddAssignable lets issues be assigned to the users with the given logins,
+// like granting them access to a repository on GitHub.
+func (m *MemoryTracker) AddAssignable(logins ...string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.assignable == nil {
+		m.assignable = map[string]bool{}
+	}
+	for _, login := range logins {
+		m.assignable[strings.ToLower(login)] = true
+	}
+}
+
+func (m *MemoryTracker) ListMilestones(ctx context.Context) ([]GitMilestone, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return append([]GitMilestone(nil), m.milestones...), nil
+}
+
+func (m *MemoryTracker) CreateMilestone(ctx context.Context, milestone GitMilestone) (GitMilestone, error) {
+	if err := ctx.Err(); err != nil {
+		return GitMilestone{}, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, existing := range m.milestones {
+		if existing.Title == milestone.Title {
+			return GitMilestone{}, &APIError{
+				StatusCode: http.StatusUnprocessableEntity,
+				Message:    "Validation Failed",
+				Errors:     []FieldError{{Resource: "Milestone", Field: "title", Code: "already_exists"}},
+			}
+		}
+	}
+	milestone.Number = len(m.milestones) + 1
+	milestone.State = StateOpen
+	m.milestones = append(m.milestones, milestone)
+	return milestone, nil
+}
+
+// createLabel stores label, defaulting its color like GitHub does. The caller
+// must hold m.mu.
+func (m *MemoryTracker) createLabel(label GitLabel) GitLabel {
+	if label.Color == "" {
+		label.Color = "ededed"
+	}
+	if m.labels == nil {
+		m.labels = map[string]GitLabel{}
+	}
+	m.labels[strings.ToLower(label.Name)] = label
+	return label
+}
+
+// CommentsOf returns the comments on the issue Id.
+func (m *MemoryTracker) CommentsOf(Id int) []GitComment {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return append([]GitComment(nil), m.comments[Id]...)
+}
+
+// lookup returns the stored issue with the given number. The caller must hold
+// m.mu.
+func (m *MemoryTracker) lookup(Id int) (*GitIssue, error) {
+	if Id < 1 || Id > len(m.issues) {
+		return nil, &APIError{StatusCode: http.StatusNotFound, Message: "Not Found"}
+	}
+	return &m.issues[Id-1], nil
+}
+
+func now() string {
+	return time.Now().UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,162 @@
+package gitclient_test
+
+import (
+	"sync"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/zszabo-rh/issues-operator/gitclient"
+)
+
+var _ = Describe("MemoryTracker", func() {
+
+	var tracker *gitclient.MemoryTracker
+
+	BeforeEach(func() {
+		tracker = gitclient.NewMemoryTracker()
+	})
+
+	Context("when a new issue is added", func() {
+		It("should be listed and retrievable by number", func() {
+			gitissue, err := tracker.AddIssue(ctx, "title", "description")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(gitissue.Id).To(Equal(1))
+			Expect(gitissue.Status).To(Equal("open"))
+
+			gitissues, err := tracker.GetIssues(ctx, gitclient.ListOptions{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(gitissues).To(HaveLen(1))
+
+			fetched, err := tracker.GetIssue(ctx, gitissue.Id)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(fetched.Title).To(Equal("title"))
+		})
+	})
+
+	Context("when an existing issue is updated and closed", func() {
+		It("should keep the latest title, description and state", func() {
+			gitissue, err := tracker.AddIssue(ctx, "title", "description")
+			Expect(err).ToNot(HaveOccurred())
+
+			updated, err := tracker.UpdateIssue(ctx, gitissue.Id, "new title", "new description")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(updated.Title).To(Equal("new title"))
+			Expect(updated.Description).To(Equal("new description"))
+
+			closed, err := tracker.CloseIssue(ctx, gitissue.Id)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(closed.Status).To(Equal("closed"))
+			Expect(closed.Title).To(Equal("new title"))
+		})
+	})
+
+	Context("when the state of an issue is edited", func() {
+		It("should record the state reason", func() {
+			gitissue, err := tracker.AddIssue(ctx, "title", "description")
+			Expect(err).ToNot(HaveOccurred())
+
+			closed, err := tracker.EditIssue(ctx, gitissue.Id, gitclient.IssueEdit{State: "closed", StateReason: "duplicate"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(closed.Status).To(Equal("closed"))
+			Expect(closed.StateReason).To(Equal("duplicate"))
+			Expect(closed.Title).To(Equal("title"))
+
+			reopened, err := tracker.EditIssue(ctx, gitissue.Id, gitclient.IssueEdit{State: "open"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(reopened.Status).To(Equal("open"))
+			Expect(reopened.StateReason).To(Equal("reopened"))
+		})
+	})
+
+	Context("when an issue is labelled", func() {
+		It("should create missing labels like GitHub does", func() {
+			_, err := tracker.CreateLabel(ctx, gitclient.GitLabel{Name: "bug", Color: "d73a4a"})
+			Expect(err).ToNot(HaveOccurred())
+			_, err = tracker.CreateLabel(ctx, gitclient.GitLabel{Name: "Bug"})
+			Expect(gitclient.IsAlreadyExists(err)).To(BeTrue())
+
+			gitissue, err := tracker.AddIssue(ctx, "title", "description")
+			Expect(err).ToNot(HaveOccurred())
+			labels := []string{"BUG", "new"}
+			gitissue, err = tracker.EditIssue(ctx, gitissue.Id, gitclient.IssueEdit{Labels: &labels})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(gitissue.Labels).To(Equal([]gitclient.GitLabel{{Name: "bug", Color: "d73a4a"}, {Name: "new", Color: "ededed"}}))
+		})
+	})
+
+	Context("when an issue is assigned", func() {
+		It("should reject users who cannot be assigned", func() {
+			tracker.AddAssignable("octocat")
+			gitissue, err := tracker.AddIssue(ctx, "title", "description")
+			Expect(err).ToNot(HaveOccurred())
+
+			assignees := []string{"octocat", "stranger"}
+			_, err = tracker.EditIssue(ctx, gitissue.Id, gitclient.IssueEdit{Title: "retitled", Assignees: &assignees})
+			Expect(gitclient.IsValidation(err)).To(BeTrue())
+
+			assignees = []string{"octocat"}
+			gitissue, err = tracker.EditIssue(ctx, gitissue.Id, gitclient.IssueEdit{Assignees: &assignees})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(gitissue.Title).To(Equal("title"))
+			Expect(gitissue.Assignees).To(Equal([]gitclient.GitUser{{Login: "octocat"}}))
+		})
+	})
+
+	Context("when a non-existing issue is requested", func() {
+		It("should return an error", func() {
+			_, err := tracker.GetIssue(ctx, 999)
+			Expect(gitclient.IsNotFound(err)).To(BeTrue())
+			_, err = tracker.UpdateIssue(ctx, 999, "title", "description")
+			Expect(err).To(HaveOccurred())
+			_, err = tracker.CloseIssue(ctx, 999)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("when issues are added concurrently", func() {
+		It("should assign unique numbers", func() {
+			var wg sync.WaitGroup
+			for i := 0; i < 20; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					defer GinkgoRecover()
+					_, err := tracker.AddIssue(ctx, "title", "description")
+					Expect(err).ToNot(HaveOccurred())
+				}()
+			}
+			wg.Wait()
+
+			gitissues, err := tracker.GetIssues(ctx, gitclient.ListOptions{})
+			Expect(err).ToNot(HaveOccurred())
+			seen := map[int]bool{}
+			for _, gitissue := range gitissues {
+				seen[gitissue.Id] = true
+			}
+			Expect(seen).To(HaveLen(20))
+		})
+	})
+
+	Context("when the factory is asked twice for the same repository", func() {
+		It("should return the same tracker", func() {
+			factory := gitclient.NewMemoryTrackerFactory()
+			first, err := factory(gitclient.RepoRef{Host: "github.com", Owner: "myrepo", Name: "myuser"})
+			Expect(err).ToNot(HaveOccurred())
+			_, err = first.AddIssue(ctx, "title", "description")
+			Expect(err).ToNot(HaveOccurred())
+
+			second, err := factory(gitclient.RepoRef{Host: "github.com", Owner: "myrepo", Name: "myuser"})
+			Expect(err).ToNot(HaveOccurred())
+			gitissues, err := second.GetIssues(ctx, gitclient.ListOptions{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(gitissues).To(HaveLen(1))
+
+			other, err := factory(gitclient.RepoRef{Host: "github.com", Owner: "myrepo", Name: "other"})
+			Expect(err).ToNot(HaveOccurred())
+			gitissues, err = other.GetIssues(ctx, gitclient.ListOptions{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(gitissues).To(BeEmpty())
+		})
+	})
+})
@@ -0,0 +1,135 @@
+package gitclient
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Tokens are identified by their fingerprint, a truncated SHA-256 hash, so
+// that metrics never expose credentials.
+var (
+	tokenRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "issues_operator_github_token_requests_total",
+		Help: "Requests made to the GitHub API with each pooled token.",
+	}, []string{"token"})
+
+	tokenRateLimitLimit = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "issues_operator_github_token_rate_limit_limit",
+		Help: "Requests each pooled token may make per rate limit window.",
+	}, []string{"token"})
+
+	tokenQuarantinedUntil = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "issues_operator_github_token_quarantined_until_seconds",
+		Help: "Unix time until which each pooled token is left out after hitting a rate limit.",
+	}, []string{"token"})
+
+	// Credentials are labelled by name rather than fingerprint, so that
+	// rotating a token does not start a new series.
+	rateLimitRemaining = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "issues_operator_github_rate_limit_remaining",
+		Help: "Requests each credential has left in the current rate limit window, as last reported by GitHub.",
+	}, []string{"host", "credential"})
+)
+
+// Requests are labelled with the path template of the endpoint rather than
+// the path itself, so that the number of series does not grow with the
+// number of repositories and issues.
+var (
+	apiRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "issues_operator_github_requests_total",
+		Help: "Requests made to the GitHub API, by host, method, endpoint and status code.",
+	}, []string{"host", "method", "endpoint", "code"})
+
+	apiRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "issues_operator_github_request_duration_seconds",
+		Help:    "Time taken by requests to the GitHub API, including reading the response.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"host", "method", "endpoint", "code"})
+)
+
+// RegisterMetrics registers the gitclient metrics with reg.
+func RegisterMetrics(reg prometheus.Registerer) error {
+	for _, c := range []prometheus.Collector{
+		tokenRequests,
+		tokenRateLimitLimit,
+		tokenQuarantinedUntil,
+		rateLimitRemaining,
+		apiRequests,
+		apiRequestDuration,
+	} {
+		if err := reg.Register(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// observeRequest records a request to the GitHub API that was answered with
+// status code, or failed without a response when code is 0.
+func observeRequest(method string, u *url.URL, code int, elapsed time.Duration) {
+	status := "error"
+	if code != 0 {
+		status = strconv.Itoa(code)
+	}
+	endpoint := endpointTemplate(u.Path)
+	apiRequests.WithLabelValues(u.Host, method, endpoint, status).Inc()
+	apiRequestDuration.WithLabelValues(u.Host, method, endpoint, status).Observe(elapsed.Seconds())
+}
+
+// observeRateLimitRemaining records the rate limit GitHub reported for
+// credential in response to a request to rawURL.
+func observeRateLimitRemaining(rawURL string, credential string, rateLimit RateLimit) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return
+	}
+	rateLimitRemaining.WithLabelValues(u.Host, credential).Set(float64(rateLimit.Remaining))
+}
+
+// endpointTemplate replaces the variable segments of an API path with
+// placeholders, turning /repos/octo/hello/issues/42 into
+// /repos/{owner}/{repo}/issues/{number}. The prefix GitHub Enterprise Server
+// serves its API under is dropped.
+func endpointTemplate(path string) string {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for i, segment := range segments {
+		if segment == "api" && i+1 < len(segments) && segments[i+1] == "v3" {
+			segments = segments[i+2:]
+			break
+		}
+	}
+
+	template := make([]string, 0, len(segments))
+	for i := 0; i < len(segments); i++ {
+		segment := segments[i]
+		switch {
+		case segment == "repos" && i+2 < len(segments):
+			template = append(template, segment, "{owner}", "{repo}")
+			i += 2
+		case (segment == "orgs" || segment == "users") && i+1 < len(segments):
+			template = append(template, segment, "{owner}")
+			i++
+		case (segment == "labels" || segment == "assignees") && i+1 < len(segments):
+			placeholder := "{name}"
+			if segment == "assignees" {
+				placeholder = "{login}"
+			}
+			template = append(template, segment, placeholder)
+			i++
+		case isNumber(segment):
+			template = append(template, "{number}")
+		default:
+			template = append(template, segment)
+		}
+	}
+	return "/" + strings.Join(template, "/")
+}
+
+func isNumber(s string) bool {
+	_, err := strconv.ParseUint(s, 10, 64)
+	return err == nil
+}
@@ -0,0 +1,154 @@
+package gitclient_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/zszabo-rh/issues-operator/gitclient"
+)
+
+var _ = Describe("Metrics", func() {
+
+	var (
+		server   *httptest.Server
+		client   *gitclient.GitClient
+		registry *prometheus.Registry
+		host     string
+	)
+
+	BeforeEach(func() {
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-RateLimit-Limit", "5000")
+			w.Header().Set("X-RateLimit-Remaining", "4321")
+			w.Header().Set("X-RateLimit-Reset", "1700000000")
+			if r.URL.Path == "/repos/myrepo/myuser/issues/404" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			fmt.Fprint(w, `{"number":42,"title":"issue","state":"open"}`)
+		}))
+		serverURL, err := url.Parse(server.URL)
+		Expect(err).ToNot(HaveOccurred())
+		host = serverURL.Host
+
+		client, err = gitclient.NewGitClient("git@github.com:myrepo/myuser.git",
+			gitclient.WithBaseURL(server.URL), gitclient.WithToken("abc123"))
+		Expect(err).ToNot(HaveOccurred())
+
+		registry = prometheus.NewRegistry()
+		Expect(gitclient.RegisterMetrics(registry)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	// series returns the metrics of the named family whose labels include
+	// labels.
+	series := func(name string, labels map[string]string) []*dto.Metric {
+		families, err := registry.Gather()
+		Expect(err).ToNot(HaveOccurred())
+		var matched []*dto.Metric
+		for _, family := range families {
+			if family.GetName() != name {
+				continue
+			}
+		metrics:
+			for _, metric := range family.GetMetric() {
+				values := map[string]string{}
+				for _, pair := range metric.GetLabel() {
+					values[pair.GetName()] = pair.GetValue()
+				}
+				for k, v := range labels {
+					if values[k] != v {
+						continue metrics
+					}
+				}
+				matched = append(matched, metric)
+			}
+		}
+		return matched
+	}
+
+	It("should count requests by endpoint template and status code", func() {
+		_, err := client.GetIssue(ctx, 42)
+		Expect(err).ToNot(HaveOccurred())
+		_, err = client.GetIssue(ctx, 404)
+		Expect(err).To(HaveOccurred())
+
+		ok := series("issues_operator_github_requests_total", map[string]string{
+			"host": host, "method": "GET", "endpoint": "/repos/{owner}/{repo}/issues/{number}", "code": "200",
+		})
+		Expect(ok).To(HaveLen(1))
+		Expect(ok[0].GetCounter().GetValue()).To(BeEquivalentTo(1))
+
+		notFound := series("issues_operator_github_requests_total", map[string]string{
+			"host": host, "endpoint": "/repos/{owner}/{repo}/issues/{number}", "code": "404",
+		})
+		Expect(notFound).To(HaveLen(1))
+		Expect(notFound[0].GetCounter().GetValue()).To(BeEquivalentTo(1))
+
+		durations := series("issues_operator_github_request_duration_seconds", map[string]string{"host": host})
+		Expect(durations).To(HaveLen(2))
+		Expect(durations[0].GetHistogram().GetSampleCount()).To(BeEquivalentTo(1))
+	})
+
+	It("should count requests that got no response", func() {
+		server.Close()
+
+		_, err := client.GetIssue(ctx, 42)
+		Expect(err).To(HaveOccurred())
+
+		failed := series("issues_operator_github_requests_total", map[string]string{"host": host, "code": "error"})
+		Expect(failed).To(HaveLen(1))
+	})
+
+	It("should report the remaining rate limit of the credential", func() {
+		_, err := client.GetIssue(ctx, 42)
+		Expect(err).ToNot(HaveOccurred())
+
+		remaining := series("issues_operator_github_rate_limit_remaining", map[string]string{"host": host, "credential": "static"})
+		Expect(remaining).To(HaveLen(1))
+		Expect(remaining[0].GetGauge().GetValue()).To(BeEquivalentTo(4321))
+	})
+
+	It("should keep reporting a rotated token under the name of its credential", func() {
+		path := filepath.Join(GinkgoT().TempDir(), "token")
+		Expect(os.WriteFile(path, []byte("first"), 0o600)).To(Succeed())
+		client, err := gitclient.NewGitClient("git@github.com:myrepo/myuser.git",
+			gitclient.WithBaseURL(server.URL), gitclient.WithTokenSource(gitclient.FileToken(path)))
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = client.GetIssue(ctx, 42)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(os.WriteFile(path, []byte("second token"), 0o600)).To(Succeed())
+		_, err = client.GetIssue(ctx, 42)
+		Expect(err).ToNot(HaveOccurred())
+
+		remaining := series("issues_operator_github_rate_limit_remaining", map[string]string{"host": host})
+		Expect(remaining).To(HaveLen(1))
+		Expect(remaining[0].GetLabel()).To(ContainElement(HaveField("GetValue()", "file:"+path)))
+	})
+
+	It("should report the remaining rate limit under the given credential name", func() {
+		client, err := gitclient.NewGitClient("git@github.com:myrepo/myuser.git",
+			gitclient.WithBaseURL(server.URL), gitclient.WithToken("abc123"), gitclient.WithCredentialName("secret:default/github"))
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = client.GetIssue(ctx, 42)
+		Expect(err).ToNot(HaveOccurred())
+
+		remaining := series("issues_operator_github_rate_limit_remaining", map[string]string{"host": host})
+		Expect(remaining).To(HaveLen(1))
+		Expect(remaining[0].GetLabel()).To(ContainElement(HaveField("GetValue()", "secret:default/github")))
+	})
+})
@@ -0,0 +1,83 @@
+package gitclient
+
+import (
+	"net/http"
+	"time"
+)
+
+// Option configures a GitClient created by NewGitClient.
+type Option func(*options)
+
+type options struct {
+	baseURL    string
+	tokens     TokenSource
+	app        *App
+	timeout    time.Duration
+	httpClient *http.Client
+	hosts      *Hosts
+	credential string
+}
+
+// WithBaseURL points the client at a different GitHub API endpoint than the
+// one configured for the repository host.
+func WithBaseURL(url string) Option {
+	return func(o *options) {
+		o.baseURL = url
+	}
+}
+
+// WithToken authenticates with token instead of the one configured for the
+// repository host.
+func WithToken(token string) Option {
+	return WithTokenSource(StaticToken(token))
+}
+
+// WithTokenSource authenticates with the tokens supplied by tokens instead of
+// the one configured for the repository host.
+func WithTokenSource(tokens TokenSource) Option {
+	return func(o *options) {
+		o.tokens = tokens
+	}
+}
+
+// WithCredentialName names the credential the client authenticates with in
+// metrics, such as the Secret a token was read from. It defaults to a name
+// derived from the token source, e.g. the environment variable or file the
+// token is read from.
+func WithCredentialName(name string) Option {
+	return func(o *options) {
+		o.credential = name
+	}
+}
+
+// WithApp authenticates as the GitHub App app for repositories on the app's
+// host, unless WithToken or WithTokenSource are given as well.
+func WithApp(app *App) Option {
+	return func(o *options) {
+		o.app = app
+	}
+}
+
+// WithTimeout bounds each request to the GitHub API, including reading the
+// response, instead of DefaultTimeout.
+func WithTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.timeout = timeout
+	}
+}
+
+// WithHTTPClient sends requests through httpClient. Sharing one client, for
+// example from NewHTTPClient, between GitClients lets them reuse connections.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(o *options) {
+		o.httpClient = httpClient
+	}
+}
+
+// WithHosts resolves the API endpoint and token of a repository from hosts.
+// Without it only github.com is known, with its token in GITTOKEN.
+func WithHosts(hosts *Hosts) Option {
+	return func(o *options) {
+		o.hosts = hosts
+	}
+}
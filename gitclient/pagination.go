@@ -0,0 +1,110 @@
+package gitclient
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// ListOptions filters the issues returned by GetIssues and IterateIssues.
+type ListOptions struct {
+	// State is one of "open", "closed" or "all". GitHub lists open issues
+	// when it is empty.
+	State string
+	// Labels restricts the result to issues carrying all of these labels.
+	Labels []string
+	// PerPage is the number of issues fetched per request, at most 100.
+	// GitHub uses 30 when it is zero.
+	PerPage int
+}
+
+// query encodes opts as URL query parameters.
+func (opts ListOptions) query() url.Values {
+	query := url.Values{}
+	if opts.State != "" {
+		query.Set("state", opts.State)
+	}
+	if len(opts.Labels) > 0 {
+		query.Set("labels", strings.Join(opts.Labels, ","))
+	}
+	if opts.PerPage > 0 {
+		query.Set("per_page", fmt.Sprint(opts.PerPage))
+	}
+	return query
+}
+
+// matches reports whether gitissue passes the filters in opts.
+func (opts ListOptions) matches(gitissue GitIssue) bool {
+	state := opts.State
+	if state == "" {
+		state = "open"
+	}
+	if state != "all" && gitissue.Status != state {
+		return false
+	}
+	for _, want := range opts.Labels {
+		found := false
+		for _, label := range gitissue.Labels {
+			if label.Name == want {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// pageIssue is an entry of the issues list. The list endpoint also returns
+// pull requests, which carry a pull_request field.
+type pageIssue struct {
+	GitIssue
+	PullRequest *struct{} `json:"pull_request,omitempty"`
+}
+
+// IterateIssues calls fn for every issue matching opts, fetching further pages
+// as needed. Iteration stops early when fn returns false.
+func (g *GitClient) IterateIssues(ctx context.Context, opts ListOptions, fn func(GitIssue) bool) error {
+	next := g.repo
+	if query := opts.query().Encode(); query != "" {
+		next += "?" + query
+	}
+
+	for next != "" {
+		var page []pageIssue
+		header, err := g.do(ctx, "GET", next, nil, &page)
+		if err != nil {
+			return err
+		}
+		for _, entry := range page {
+			if entry.PullRequest != nil {
+				continue
+			}
+			if !fn(entry.GitIssue) {
+				return nil
+			}
+		}
+		next = nextPageURL(header.Get("Link"))
+	}
+	return nil
+}
+
+// nextPageURL returns the rel="next" target of a Link header, or "" if there
+// is no next page.
+func nextPageURL(link string) string {
+	for _, part := range strings.Split(link, ",") {
+		target, params, ok := strings.Cut(part, ";")
+		if !ok {
+			continue
+		}
+		for _, param := range strings.Split(params, ";") {
+			if strings.TrimSpace(param) == `rel="next"` {
+				return strings.Trim(strings.TrimSpace(target), "<>")
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,115 @@
+package gitclient_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/zszabo-rh/issues-operator/gitclient"
+)
+
+var _ = Describe("Pagination", func() {
+
+	var (
+		server   *httptest.Server
+		client   *gitclient.GitClient
+		requests []*http.Request
+	)
+
+	BeforeEach(func() {
+		requests = nil
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests = append(requests, r)
+			page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+			if page == 0 {
+				page = 1
+			}
+
+			// Three pages of two entries each; the very last entry is a pull
+			// request, which must be skipped.
+			entries := []map[string]any{}
+			for i := 1; i <= 2; i++ {
+				number := (page-1)*2 + i
+				entry := map[string]any{"number": number, "title": fmt.Sprintf("issue %d", number), "state": "open"}
+				if number == 6 {
+					entry["pull_request"] = map[string]any{"url": "https://example.com"}
+				}
+				entries = append(entries, entry)
+			}
+			if page < 3 {
+				next := fmt.Sprintf("%s%s?page=%d&per_page=2", server.URL, r.URL.Path, page+1)
+				w.Header().Set("Link", fmt.Sprintf(`<%s>; rel="next", <%s?page=3>; rel="last"`, next, server.URL+r.URL.Path))
+			}
+			Expect(json.NewEncoder(w).Encode(entries)).To(Succeed())
+		}))
+
+		var err error
+		client, err = gitclient.NewGitClient("git@github.com:myrepo/myuser.git",
+			gitclient.WithBaseURL(server.URL), gitclient.WithToken("abc123"))
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	Context("when the issue list spans several pages", func() {
+		It("should follow the Link headers to the last page", func() {
+			gitissues, err := client.GetIssues(ctx, gitclient.ListOptions{PerPage: 2})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(gitissues).To(HaveLen(5))
+			Expect(gitissues[4].Id).To(Equal(5))
+			Expect(requests).To(HaveLen(3))
+		})
+
+		It("should pass the filters as query parameters", func() {
+			_, err := client.GetIssues(ctx, gitclient.ListOptions{State: "all", Labels: []string{"bug", "ui"}, PerPage: 2})
+			Expect(err).ToNot(HaveOccurred())
+			query := requests[0].URL.Query()
+			Expect(query.Get("state")).To(Equal("all"))
+			Expect(query.Get("labels")).To(Equal("bug,ui"))
+			Expect(query.Get("per_page")).To(Equal("2"))
+			Expect(requests[0].URL.Path).To(Equal("/repos/myrepo/myuser/issues"))
+		})
+
+		It("should stop fetching when the callback returns false", func() {
+			seen := 0
+			err := client.IterateIssues(ctx, gitclient.ListOptions{}, func(gitissue gitclient.GitIssue) bool {
+				seen++
+				return gitissue.Id < 2
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(seen).To(Equal(2))
+			Expect(requests).To(HaveLen(1))
+		})
+	})
+
+	Context("when the memory tracker is filtered", func() {
+		It("should apply the state filter", func() {
+			tracker := gitclient.NewMemoryTracker()
+			_, err := tracker.AddIssue(ctx, "open", "")
+			Expect(err).ToNot(HaveOccurred())
+			closed, err := tracker.AddIssue(ctx, "closed", "")
+			Expect(err).ToNot(HaveOccurred())
+			_, err = tracker.CloseIssue(ctx, closed.Id)
+			Expect(err).ToNot(HaveOccurred())
+
+			gitissues, err := tracker.GetIssues(ctx, gitclient.ListOptions{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(gitissues).To(HaveLen(1))
+
+			gitissues, err = tracker.GetIssues(ctx, gitclient.ListOptions{State: "all"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(gitissues).To(HaveLen(2))
+
+			gitissues, err = tracker.GetIssues(ctx, gitclient.ListOptions{State: "all", Labels: []string{"bug"}})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(gitissues).To(BeEmpty())
+		})
+	})
+})
@@ -0,0 +1,199 @@
+package gitclient
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// TokenPool is a TokenSource spreading requests over several tokens. Every
+// request gets the token with the most remaining quota according to the
+// rate limit headers of earlier responses, and tokens that hit a rate limit
+// are left out until it resets.
+type TokenPool struct {
+	sources []TokenSource
+
+	mu sync.Mutex
+	// states is keyed by token fingerprint, so that the pool keeps no
+	// tokens in memory beyond those its sources hold.
+	states map[string]*tokenState
+}
+
+// tokenState is what a TokenPool knows about the quota of one token.
+type tokenState struct {
+	fingerprint string
+	// source is the index of the TokenSource the token came from.
+	source    int
+	rateLimit RateLimit
+	// known is false until a response reported the token's rate limit.
+	known bool
+	// quarantinedUntil is when a rate limit hit by the token resets.
+	quarantinedUntil time.Time
+}
+
+// NewTokenPool returns a TokenPool drawing tokens from sources.
+func NewTokenPool(sources ...TokenSource) *TokenPool {
+	return &TokenPool{sources: sources, states: map[string]*tokenState{}}
+}
+
+// Token returns the token with the most remaining quota. Tokens whose quota
+// is not known yet are preferred, so that every token gets used. If every
+// token is rate limited, Token returns a *RateLimitError retrying when the
+// first of them resets.
+func (p *TokenPool) Token(ctx context.Context) (string, error) {
+	var tokens []string
+	var sources []int
+	var errs []error
+	for i, source := range p.sources {
+		token, err := source.Token(ctx)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		tokens = append(tokens, token)
+		sources = append(sources, i)
+	}
+	if len(tokens) == 0 {
+		if len(errs) == 0 {
+			return "", errors.New("no token: the token pool is empty")
+		}
+		return "", errors.Join(errs...)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if len(errs) == 0 {
+		p.prune(tokens)
+	}
+
+	now := time.Now()
+	var best string
+	var bestState *tokenState
+	var retryAt time.Time
+	for i, token := range tokens {
+		state := p.state(token)
+		state.source = sources[i]
+		if now.Before(state.quarantinedUntil) {
+			if retryAt.IsZero() || state.quarantinedUntil.Before(retryAt) {
+				retryAt = state.quarantinedUntil
+			}
+			continue
+		}
+		if bestState == nil || state.betterThan(bestState) {
+			best, bestState = token, state
+		}
+	}
+	if bestState == nil {
+		return "", &RateLimitError{RetryAt: retryAt}
+	}
+
+	// Count the request against the token right away, so that concurrent
+	// requests spread over the pool before the response tells us more.
+	if bestState.known && bestState.rateLimit.Remaining > 0 {
+		bestState.rateLimit.Remaining--
+	}
+	tokenRequests.WithLabelValues(bestState.fingerprint).Inc()
+	return best, nil
+}
+
+// observeRateLimit records the rate limit GitHub reported for token.
+func (p *TokenPool) observeRateLimit(token string, rateLimit RateLimit) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	state := p.state(token)
+	state.rateLimit = rateLimit
+	state.known = true
+	if rateLimit.Remaining == 0 && rateLimit.Reset.After(time.Now()) {
+		state.quarantine(rateLimit.Reset)
+	}
+	tokenRateLimitLimit.WithLabelValues(state.fingerprint).Set(float64(rateLimit.Limit))
+}
+
+// invalidate passes on that GitHub rejected token to the source it came from,
+// so that sources caching their tokens fetch a new one.
+func (p *TokenPool) invalidate(token string) {
+	p.mu.Lock()
+	state, ok := p.states[tokenFingerprint(token)]
+	p.mu.Unlock()
+	if !ok {
+		return
+	}
+	if inv, ok := p.sources[state.source].(invalidator); ok {
+		inv.invalidate(token)
+	}
+}
+
+// credentialName names token by the position of its source in the pool.
+func (p *TokenPool) credentialName(token string) string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	state, ok := p.states[tokenFingerprint(token)]
+	if !ok {
+		return "pool"
+	}
+	return poolCredentialName(state.source)
+}
+
+// poolCredentialName names the credential of the source at index i of a pool.
+func poolCredentialName(i int) string {
+	return fmt.Sprintf("pool:%d", i)
+}
+
+// observeRateLimitError quarantines token until a rate limit it hit resets.
+func (p *TokenPool) observeRateLimitError(token string, err *RateLimitError) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.state(token).quarantine(err.RetryAt)
+}
+
+func (p *TokenPool) state(token string) *tokenState {
+	fingerprint := tokenFingerprint(token)
+	state, ok := p.states[fingerprint]
+	if !ok {
+		state = &tokenState{fingerprint: fingerprint}
+		p.states[fingerprint] = state
+	}
+	return state
+}
+
+// prune forgets the state of tokens the sources no longer return, such as
+// rotated ones.
+func (p *TokenPool) prune(tokens []string) {
+	current := make(map[string]bool, len(tokens))
+	for _, token := range tokens {
+		current[tokenFingerprint(token)] = true
+	}
+	for fingerprint := range p.states {
+		if !current[fingerprint] {
+			delete(p.states, fingerprint)
+		}
+	}
+}
+
+func (s *tokenState) betterThan(other *tokenState) bool {
+	if s.known != other.known {
+		return !s.known
+	}
+	return s.rateLimit.Remaining > other.rateLimit.Remaining
+}
+
+func (s *tokenState) quarantine(until time.Time) {
+	if until.After(s.quarantinedUntil) {
+		s.quarantinedUntil = until
+	}
+	tokenQuarantinedUntil.WithLabelValues(s.fingerprint).Set(float64(s.quarantinedUntil.Unix()))
+}
+
+// tokenFingerprint identifies token in metrics and logs without revealing it.
+func tokenFingerprint(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return "sha256:" + hex.EncodeToString(sum[:])[:12]
+}
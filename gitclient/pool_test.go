@@ -0,0 +1,129 @@
+package gitclient_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/zszabo-rh/issues-operator/gitclient"
+)
+
+var _ = Describe("Token pool", func() {
+
+	var (
+		server    *httptest.Server
+		client    *gitclient.GitClient
+		mu        sync.Mutex
+		used      []string
+		remaining map[string]int
+		reset     time.Time
+	)
+
+	BeforeEach(func() {
+		used = nil
+		remaining = map[string]int{"Bearer first": 10, "Bearer second": 100}
+		reset = time.Now().Add(time.Hour).Truncate(time.Second)
+
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			auth := r.Header.Get("Authorization")
+			used = append(used, auth)
+			w.Header().Set("X-RateLimit-Limit", "5000")
+			w.Header().Set("X-RateLimit-Remaining", fmt.Sprint(remaining[auth]))
+			w.Header().Set("X-RateLimit-Reset", fmt.Sprint(reset.Unix()))
+			if remaining[auth] == 0 {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			remaining[auth]--
+			fmt.Fprint(w, `{"number": 1}`)
+		}))
+
+		pool := gitclient.NewTokenPool(gitclient.StaticToken("first"), gitclient.StaticToken("second"))
+		var err error
+		client, err = gitclient.NewGitClient("myuser/myrepo", gitclient.WithBaseURL(server.URL), gitclient.WithTokenSource(pool))
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("should try every token and then prefer the one with the most quota left", func() {
+		for i := 0; i < 4; i++ {
+			_, err := client.GetIssue(ctx, 1)
+			Expect(err).ToNot(HaveOccurred())
+		}
+		Expect(used).To(Equal([]string{"Bearer first", "Bearer second", "Bearer second", "Bearer second"}))
+	})
+
+	It("should leave out rate limited tokens until they reset", func() {
+		remaining["Bearer second"] = 0
+
+		_, err := client.GetIssue(ctx, 1)
+		Expect(err).ToNot(HaveOccurred())
+		_, err = client.GetIssue(ctx, 1)
+		retryAt, ok := gitclient.RetryAt(err)
+		Expect(ok).To(BeTrue())
+		Expect(retryAt).To(BeTemporally("==", reset))
+
+		for i := 0; i < 3; i++ {
+			_, err = client.GetIssue(ctx, 1)
+			Expect(err).ToNot(HaveOccurred())
+		}
+		Expect(used).To(Equal([]string{"Bearer first", "Bearer second", "Bearer first", "Bearer first", "Bearer first"}))
+	})
+
+	It("should fail with a rate limit error when every token is exhausted", func() {
+		remaining["Bearer first"] = 0
+		remaining["Bearer second"] = 0
+
+		_, err := client.GetIssue(ctx, 1)
+		Expect(err).To(HaveOccurred())
+		_, err = client.GetIssue(ctx, 1)
+		Expect(err).To(HaveOccurred())
+		Expect(used).To(HaveLen(2))
+
+		_, err = client.GetIssue(ctx, 1)
+		retryAt, ok := gitclient.RetryAt(err)
+		Expect(ok).To(BeTrue())
+		Expect(retryAt).To(BeTemporally("==", reset))
+		Expect(used).To(HaveLen(2))
+	})
+
+	It("should report usage per token without revealing the tokens", func() {
+		registry := prometheus.NewRegistry()
+		Expect(gitclient.RegisterMetrics(registry)).To(Succeed())
+
+		_, err := client.GetIssue(ctx, 1)
+		Expect(err).ToNot(HaveOccurred())
+
+		families, err := registry.Gather()
+		Expect(err).ToNot(HaveOccurred())
+		names := []string{}
+		for _, family := range families {
+			names = append(names, family.GetName())
+			for _, metric := range family.GetMetric() {
+				for _, label := range metric.GetLabel() {
+					if label.GetName() == "token" {
+						Expect(label.GetValue()).To(HavePrefix("sha256:"))
+						Expect(strings.Contains(label.GetValue(), "first")).To(BeFalse())
+					}
+				}
+			}
+		}
+		Expect(names).To(ContainElements(
+			"issues_operator_github_token_requests_total",
+			"issues_operator_github_token_rate_limit_limit",
+		))
+	})
+})
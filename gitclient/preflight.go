@@ -0,0 +1,154 @@
+package gitclient
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// tokenExpirationHeader carries the expiry of personal access tokens created
+// with one.
+const tokenExpirationHeader = "GitHub-Authentication-Token-Expiration"
+
+// Credential is a credential configured for a GitHub host.
+type Credential struct {
+	Host   string
+	APIURL string
+	Tokens TokenSource
+	// Name identifies the credential in metrics. It defaults to a name
+	// derived from Tokens, e.g. the file the token is read from.
+	Name string
+}
+
+// CredentialInfo is what GitHub reports about a credential.
+type CredentialInfo struct {
+	// Login is the user or app the credential authenticates as.
+	Login string
+	// Fingerprint identifies the token without revealing it.
+	Fingerprint string
+	// Credential names the credential the token belongs to. Unlike the
+	// fingerprint, it stays the same when the token is rotated.
+	Credential string
+	// Scopes are the OAuth scopes of a classic personal access token. They
+	// are nil for fine-grained tokens and GitHub Apps, whose permissions
+	// GitHub does not report in headers.
+	Scopes []string
+	// ExpiresAt is when the token expires, or zero if it does not.
+	ExpiresAt time.Time
+	// ReadOnly is true if the credential cannot create or edit issues.
+	ReadOnly bool
+}
+
+// Credentials returns the credentials configured for every host, listing the
+// tokens of a pool separately.
+func (h *Hosts) Credentials() []Credential {
+	var credentials []Credential
+	for host, cfg := range h.hosts {
+		tokens := h.tokens[host]
+		if pool, ok := tokens.(*TokenPool); ok {
+			for i, source := range pool.sources {
+				credentials = append(credentials, Credential{Host: host, APIURL: cfg.APIURL, Tokens: source, Name: poolCredentialName(i)})
+			}
+			continue
+		}
+		credentials = append(credentials, Credential{Host: host, APIURL: cfg.APIURL, Tokens: tokens})
+	}
+	return credentials
+}
+
+// CheckCredential validates cred against GitHub. Personal access tokens are
+// checked with GET /user and installation tokens, which cannot read /user,
+// with GET /installation/repositories.
+func CheckCredential(ctx context.Context, httpClient *http.Client, cred Credential) (CredentialInfo, error) {
+	if httpClient == nil {
+		httpClient = defaultHTTPClient
+	}
+	ctx, cancel := context.WithTimeout(ctx, DefaultTimeout)
+	defer cancel()
+
+	name := cred.Name
+	if name == "" {
+		name = credentialNameOf(cred.Tokens, "")
+	}
+	token, err := cred.Tokens.Token(ctx)
+	if err != nil {
+		return CredentialInfo{Credential: name}, err
+	}
+	info := CredentialInfo{Fingerprint: tokenFingerprint(token), Credential: name}
+	apiURL := strings.TrimSuffix(cred.APIURL, "/")
+
+	var user struct {
+		Login string `json:"login"`
+	}
+	header, err := doRequest(ctx, httpClient, http.MethodGet, apiURL+"/user", token, nil, &user)
+	if IsForbidden(err) {
+		var repos struct{}
+		header, err = doRequest(ctx, httpClient, http.MethodGet, apiURL+"/installation/repositories?per_page=1", token, nil, &repos)
+	}
+	if err != nil {
+		return info, err
+	}
+	info.Login = user.Login
+
+	if scopes, ok := header[http.CanonicalHeaderKey("X-OAuth-Scopes")]; ok {
+		info.Scopes = []string{}
+		for _, scope := range strings.Split(strings.Join(scopes, ","), ",") {
+			if scope = strings.TrimSpace(scope); scope != "" {
+				info.Scopes = append(info.Scopes, scope)
+			}
+		}
+		info.ReadOnly = !canWriteIssues(info.Scopes)
+	}
+	info.ExpiresAt = parseTokenExpiration(header.Get(tokenExpirationHeader))
+	return info, nil
+}
+
+// Check validates the app's private key with GET /app and reports whether
+// the app may write issues.
+func (a *App) Check(ctx context.Context) (CredentialInfo, error) {
+	ctx, cancel := context.WithTimeout(ctx, DefaultTimeout)
+	defer cancel()
+
+	name := fmt.Sprintf("app:%d", a.id)
+	jwt, err := a.jwt(time.Now())
+	if err != nil {
+		return CredentialInfo{Credential: name}, err
+	}
+	var app struct {
+		Slug        string            `json:"slug"`
+		Permissions map[string]string `json:"permissions"`
+	}
+	if _, err := doRequest(ctx, a.httpClient, http.MethodGet, a.apiURL+"/app", jwt, nil, &app); err != nil {
+		return CredentialInfo{Credential: name}, err
+	}
+	return CredentialInfo{
+		Login:      app.Slug + "[bot]",
+		Credential: name,
+		ReadOnly:   app.Permissions["issues"] != "write",
+	}, nil
+}
+
+// canWriteIssues reports whether a classic token with scopes may create and
+// edit issues, which takes the repo or public_repo scope.
+func canWriteIssues(scopes []string) bool {
+	for _, scope := range scopes {
+		if scope == "repo" || scope == "public_repo" {
+			return true
+		}
+	}
+	return false
+}
+
+// parseTokenExpiration parses the token expiration header, e.g.
+// "2025-01-01 00:00:00 UTC". It returns the zero time if the header is
+// missing or malformed.
+func parseTokenExpiration(value string) time.Time {
+	for _, layout := range []string{"2006-01-02 15:04:05 MST", "2006-01-02 15:04:05 -0700"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
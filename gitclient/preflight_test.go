@@ -0,0 +1,116 @@
+package gitclient_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/zszabo-rh/issues-operator/gitclient"
+)
+
+var _ = Describe("Credential checks", func() {
+
+	var (
+		server  *httptest.Server
+		handler http.HandlerFunc
+		cred    gitclient.Credential
+	)
+
+	BeforeEach(func() {
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			handler(w, r)
+		}))
+		cred = gitclient.Credential{Host: "github.com", APIURL: server.URL, Tokens: gitclient.StaticToken("abc123")}
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("should report the scopes and expiry of a classic token", func() {
+		handler = func(w http.ResponseWriter, r *http.Request) {
+			Expect(r.URL.Path).To(Equal("/user"))
+			w.Header().Set("X-OAuth-Scopes", "repo, read:org")
+			w.Header().Set("GitHub-Authentication-Token-Expiration", "2030-01-02 03:04:05 UTC")
+			fmt.Fprint(w, `{"login": "octocat"}`)
+		}
+
+		info, err := gitclient.CheckCredential(ctx, nil, cred)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(info.Login).To(Equal("octocat"))
+		Expect(info.Scopes).To(Equal([]string{"repo", "read:org"}))
+		Expect(info.ExpiresAt).To(Equal(time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)))
+		Expect(info.ReadOnly).To(BeFalse())
+		Expect(info.Fingerprint).To(HavePrefix("sha256:"))
+		Expect(info.Credential).To(Equal("static"))
+	})
+
+	It("should report a token without write scope as read-only", func() {
+		handler = func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-OAuth-Scopes", "read:user")
+			fmt.Fprint(w, `{"login": "octocat"}`)
+		}
+
+		info, err := gitclient.CheckCredential(ctx, nil, cred)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(info.ReadOnly).To(BeTrue())
+		Expect(info.ExpiresAt.IsZero()).To(BeTrue())
+	})
+
+	It("should not guess the permissions of fine-grained tokens", func() {
+		handler = func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `{"login": "octocat"}`)
+		}
+
+		info, err := gitclient.CheckCredential(ctx, nil, cred)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(info.Scopes).To(BeNil())
+		Expect(info.ReadOnly).To(BeFalse())
+	})
+
+	It("should check installation tokens against the installation", func() {
+		handler = func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/user" {
+				w.WriteHeader(http.StatusForbidden)
+				fmt.Fprint(w, `{"message": "Resource not accessible by integration"}`)
+				return
+			}
+			Expect(r.URL.Path).To(Equal("/installation/repositories"))
+			fmt.Fprint(w, `{"total_count": 1, "repositories": []}`)
+		}
+
+		_, err := gitclient.CheckCredential(ctx, nil, cred)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("should fail for a rejected token", func() {
+		handler = func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"message": "Bad credentials"}`)
+		}
+
+		_, err := gitclient.CheckCredential(ctx, nil, cred)
+		Expect(gitclient.IsUnauthorized(err)).To(BeTrue())
+	})
+
+	It("should list every token of a pool", func() {
+		hosts, err := gitclient.NewHosts(gitclient.HostConfig{
+			Host:      "github.example.com",
+			TokenPool: []gitclient.TokenConfig{{Env: "FIRST_TOKEN"}, {Env: "SECOND_TOKEN"}},
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		hostsOf := func(credentials []gitclient.Credential) []string {
+			names := []string{}
+			for _, cred := range credentials {
+				names = append(names, cred.Host)
+			}
+			return names
+		}
+		Expect(hostsOf(hosts.Credentials())).To(ConsistOf("github.com", "github.example.com", "github.example.com"))
+	})
+})
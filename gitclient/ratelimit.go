@@ -0,0 +1,97 @@
+package gitclient
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// secondaryRateLimitWait is how long to back off from a secondary rate limit
+// that came without a Retry-After header, as recommended by GitHub.
+const secondaryRateLimitWait = time.Minute
+
+// RateLimit is the rate limit state GitHub reports with every response.
+type RateLimit struct {
+	Limit     int
+	Remaining int
+	Reset     time.Time
+}
+
+// RateLimitError is returned when GitHub rejected a request because a primary
+// or secondary rate limit was exceeded.
+type RateLimitError struct {
+	RateLimit
+	// RetryAt is the earliest time the request should be retried.
+	RetryAt time.Time
+	// Response is the error GitHub answered with.
+	Response *APIError
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("github rate limit exceeded, retry at %s", e.RetryAt.Format(time.RFC3339))
+}
+
+func (e *RateLimitError) Unwrap() error {
+	if e.Response == nil {
+		return nil
+	}
+	return e.Response
+}
+
+// RetryAt reports when a request that failed with err may be retried. The
+// second return value is false if err is not a rate limit error.
+func RetryAt(err error) (time.Time, bool) {
+	var rateLimitErr *RateLimitError
+	if errors.As(err, &rateLimitErr) {
+		return rateLimitErr.RetryAt, true
+	}
+	return time.Time{}, false
+}
+
+// parseRateLimit reads the X-RateLimit-* headers. The second return value is
+// false if the response did not carry them.
+func parseRateLimit(header http.Header) (RateLimit, bool) {
+	remaining, err := strconv.Atoi(header.Get("X-RateLimit-Remaining"))
+	if err != nil {
+		return RateLimit{}, false
+	}
+	rateLimit := RateLimit{Remaining: remaining}
+	if limit, err := strconv.Atoi(header.Get("X-RateLimit-Limit")); err == nil {
+		rateLimit.Limit = limit
+	}
+	if reset, err := strconv.ParseInt(header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+		rateLimit.Reset = time.Unix(reset, 0)
+	}
+	return rateLimit, true
+}
+
+// checkRateLimit returns a RateLimitError if resp was rejected by a rate
+// limit, and nil otherwise. GitHub answers with 403 or 429 and either an
+// exhausted X-RateLimit-Remaining or a Retry-After header.
+func checkRateLimit(resp *http.Response, apiErr *APIError) *RateLimitError {
+	if resp.StatusCode != http.StatusForbidden && resp.StatusCode != http.StatusTooManyRequests {
+		return nil
+	}
+
+	rateLimit, hasRateLimit := parseRateLimit(resp.Header)
+	retryAfter, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+	hasRetryAfter := err == nil
+
+	exhausted := hasRateLimit && rateLimit.Remaining == 0
+	if !exhausted && !hasRetryAfter && resp.StatusCode != http.StatusTooManyRequests {
+		return nil
+	}
+
+	rateLimitErr := &RateLimitError{RateLimit: rateLimit, Response: apiErr}
+	switch {
+	case hasRetryAfter:
+		rateLimitErr.RetryAt = time.Now().Add(time.Duration(retryAfter) * time.Second)
+	case exhausted && !rateLimit.Reset.IsZero():
+		rateLimitErr.RetryAt = rateLimit.Reset
+	default:
+		rateLimitErr.RetryAt = time.Now().Add(secondaryRateLimitWait)
+	}
+	return rateLimitErr
+}
@@ -0,0 +1,88 @@
+package gitclient_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/zszabo-rh/issues-operator/gitclient"
+)
+
+var _ = Describe("Rate limiting", func() {
+
+	var (
+		server  *httptest.Server
+		client  *gitclient.GitClient
+		handler http.HandlerFunc
+	)
+
+	BeforeEach(func() {
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			handler(w, r)
+		}))
+
+		var err error
+		client, err = gitclient.NewGitClient("git@github.com:myrepo/myuser.git",
+			gitclient.WithBaseURL(server.URL), gitclient.WithToken("abc123"))
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	Context("when the primary rate limit is exhausted", func() {
+		It("should return a RateLimitError retrying at the reset time", func() {
+			reset := time.Now().Add(10 * time.Minute).Truncate(time.Second)
+			handler = func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("X-RateLimit-Limit", "5000")
+				w.Header().Set("X-RateLimit-Remaining", "0")
+				w.Header().Set("X-RateLimit-Reset", fmt.Sprint(reset.Unix()))
+				w.WriteHeader(http.StatusForbidden)
+			}
+
+			_, err := client.GetIssue(ctx, 1)
+			var rateLimitErr *gitclient.RateLimitError
+			Expect(err).To(BeAssignableToTypeOf(rateLimitErr))
+			rateLimitErr = err.(*gitclient.RateLimitError)
+			Expect(rateLimitErr.Limit).To(Equal(5000))
+			Expect(rateLimitErr.RetryAt).To(BeTemporally("==", reset))
+
+			retryAt, ok := gitclient.RetryAt(fmt.Errorf("wrapped: %w", err))
+			Expect(ok).To(BeTrue())
+			Expect(retryAt).To(BeTemporally("==", reset))
+		})
+	})
+
+	Context("when a secondary rate limit is hit", func() {
+		It("should honour Retry-After", func() {
+			handler = func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Retry-After", "30")
+				w.WriteHeader(http.StatusForbidden)
+			}
+
+			_, err := client.GetIssue(ctx, 1)
+			retryAt, ok := gitclient.RetryAt(err)
+			Expect(ok).To(BeTrue())
+			Expect(retryAt).To(BeTemporally("~", time.Now().Add(30*time.Second), 2*time.Second))
+		})
+	})
+
+	Context("when access is forbidden for another reason", func() {
+		It("should not be reported as rate limiting", func() {
+			handler = func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("X-RateLimit-Remaining", "4999")
+				w.WriteHeader(http.StatusForbidden)
+			}
+
+			_, err := client.GetIssue(ctx, 1)
+			Expect(err).To(HaveOccurred())
+			_, ok := gitclient.RetryAt(err)
+			Expect(ok).To(BeFalse())
+		})
+	})
+})
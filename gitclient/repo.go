@@ -0,0 +1,101 @@
+package gitclient
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// DefaultHost is the host assumed for repositories given as owner/name.
+const DefaultHost = "github.com"
+
+var (
+	ownerPattern = regexp.MustCompile(`^[A-Za-z0-9](?:[A-Za-z0-9-]*[A-Za-z0-9])?$`)
+	namePattern  = regexp.MustCompile(`^[A-Za-z0-9._-]+$`)
+)
+
+// RepoRef identifies a repository on a GitHub host.
+type RepoRef struct {
+	Host  string
+	Owner string
+	Name  string
+}
+
+// String returns the reference as host/owner/name.
+func (r RepoRef) String() string {
+	return r.Host + "/" + r.FullName()
+}
+
+// FullName returns the reference as owner/name, the form GitHub uses in API
+// paths.
+func (r RepoRef) FullName() string {
+	return r.Owner + "/" + r.Name
+}
+
+// ParseRepo parses a repository reference. It accepts the forms
+//
+//	git@github.com:owner/name.git
+//	ssh://git@github.com:22/owner/name.git
+//	https://github.com/owner/name.git
+//	owner/name
+//
+// where the .git suffix is optional and owner/name refers to DefaultHost.
+func ParseRepo(repo string) (RepoRef, error) {
+	ref, err := parseRepo(strings.TrimSpace(repo))
+	if err != nil {
+		return RepoRef{}, fmt.Errorf("invalid repository %q: %w", repo, err)
+	}
+	return ref, nil
+}
+
+func parseRepo(repo string) (RepoRef, error) {
+	if repo == "" {
+		return RepoRef{}, fmt.Errorf("empty reference")
+	}
+
+	var host, path string
+	switch {
+	case strings.Contains(repo, "://"):
+		u, err := url.Parse(repo)
+		if err != nil {
+			return RepoRef{}, err
+		}
+		switch u.Scheme {
+		case "https", "http", "ssh", "git+ssh":
+		default:
+			return RepoRef{}, fmt.Errorf("unsupported scheme %q", u.Scheme)
+		}
+		if u.RawQuery != "" || u.Fragment != "" {
+			return RepoRef{}, fmt.Errorf("unexpected query or fragment")
+		}
+		host, path = u.Hostname(), strings.TrimSuffix(u.Path, "/")
+	case strings.Contains(repo, ":"):
+		// scp-like syntax: [user@]host:owner/name
+		var userHost string
+		userHost, path, _ = strings.Cut(repo, ":")
+		if at := strings.LastIndex(userHost, "@"); at >= 0 {
+			userHost = userHost[at+1:]
+		}
+		host = userHost
+	default:
+		host, path = DefaultHost, repo
+	}
+
+	if host == "" {
+		return RepoRef{}, fmt.Errorf("missing host")
+	}
+	segments := strings.Split(strings.TrimPrefix(path, "/"), "/")
+	if len(segments) != 2 {
+		return RepoRef{}, fmt.Errorf("expected <owner>/<name> after the host, got %q", path)
+	}
+
+	owner, name := segments[0], strings.TrimSuffix(segments[1], ".git")
+	if !ownerPattern.MatchString(owner) {
+		return RepoRef{}, fmt.Errorf("invalid owner %q", owner)
+	}
+	if !namePattern.MatchString(name) || name == "." || name == ".." {
+		return RepoRef{}, fmt.Errorf("invalid name %q", segments[1])
+	}
+	return RepoRef{Host: strings.ToLower(host), Owner: owner, Name: name}, nil
+}
@@ -0,0 +1,53 @@
+package gitclient_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/zszabo-rh/issues-operator/gitclient"
+)
+
+var _ = Describe("ParseRepo", func() {
+
+	DescribeTable("accepted forms",
+		func(repo string, expected gitclient.RepoRef) {
+			ref, err := gitclient.ParseRepo(repo)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ref).To(Equal(expected))
+		},
+		Entry("scp-like SSH", "git@github.com:myuser/myrepo.git",
+			gitclient.RepoRef{Host: "github.com", Owner: "myuser", Name: "myrepo"}),
+		Entry("scp-like SSH without .git", "git@github.com:myuser/myrepo",
+			gitclient.RepoRef{Host: "github.com", Owner: "myuser", Name: "myrepo"}),
+		Entry("dots in the name", "git@github.com:myuser/my.service.git",
+			gitclient.RepoRef{Host: "github.com", Owner: "myuser", Name: "my.service"}),
+		Entry("ssh URL with port", "ssh://git@github.example.com:2222/myuser/myrepo.git",
+			gitclient.RepoRef{Host: "github.example.com", Owner: "myuser", Name: "myrepo"}),
+		Entry("HTTPS clone URL", "https://github.com/myuser/myrepo.git",
+			gitclient.RepoRef{Host: "github.com", Owner: "myuser", Name: "myrepo"}),
+		Entry("HTTPS browser URL", "https://GitHub.com/myuser/my_repo/",
+			gitclient.RepoRef{Host: "github.com", Owner: "myuser", Name: "my_repo"}),
+		Entry("owner/name", "myuser/myrepo",
+			gitclient.RepoRef{Host: "github.com", Owner: "myuser", Name: "myrepo"}),
+	)
+
+	DescribeTable("rejected forms",
+		func(repo string, message string) {
+			_, err := gitclient.ParseRepo(repo)
+			Expect(err).To(MatchError(ContainSubstring(message)))
+		},
+		Entry("empty", "", "empty reference"),
+		Entry("missing name", "git@github.com:myuser", `expected <owner>/<name> after the host, got "myuser"`),
+		Entry("API URL", "https://api.github.com/repos/myuser/myrepo/issues", "expected <owner>/<name> after the host"),
+		Entry("unsupported scheme", "ftp://github.com/myuser/myrepo", `unsupported scheme "ftp"`),
+		Entry("invalid owner", "git@github.com:my_user/myrepo.git", `invalid owner "my_user"`),
+		Entry("invalid name", "myuser/..", `invalid name ".."`),
+		Entry("missing host", "git@:myuser/myrepo", "missing host"),
+	)
+
+	It("should render the reference", func() {
+		ref := gitclient.RepoRef{Host: "github.com", Owner: "myuser", Name: "myrepo"}
+		Expect(ref.String()).To(Equal("github.com/myuser/myrepo"))
+		Expect(ref.FullName()).To(Equal("myuser/myrepo"))
+	})
+})
@@ -0,0 +1,73 @@
+package gitclient_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/zszabo-rh/issues-operator/gitclient"
+)
+
+var _ = Describe("Timeouts", func() {
+
+	var (
+		server  *httptest.Server
+		release chan struct{}
+	)
+
+	BeforeEach(func() {
+		release = make(chan struct{})
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case <-release:
+			case <-r.Context().Done():
+			}
+		}))
+	})
+
+	AfterEach(func() {
+		close(release)
+		server.Close()
+	})
+
+	Context("when GitHub does not answer in time", func() {
+		It("should give up after the configured timeout", func() {
+			client, err := gitclient.NewGitClient("git@github.com:myrepo/myuser.git",
+				gitclient.WithBaseURL(server.URL), gitclient.WithToken("abc123"),
+				gitclient.WithTimeout(50*time.Millisecond))
+			Expect(err).ToNot(HaveOccurred())
+
+			start := time.Now()
+			_, err = client.GetIssue(ctx, 1)
+			Expect(errors.Is(err, context.DeadlineExceeded)).To(BeTrue())
+			Expect(time.Since(start)).To(BeNumerically("<", 5*time.Second))
+		})
+	})
+
+	Context("when the caller cancels the context", func() {
+		It("should abort the request", func() {
+			client, err := gitclient.NewGitClient("git@github.com:myrepo/myuser.git",
+				gitclient.WithBaseURL(server.URL), gitclient.WithToken("abc123"))
+			Expect(err).ToNot(HaveOccurred())
+
+			cancelCtx, cancel := context.WithCancel(ctx)
+			time.AfterFunc(50*time.Millisecond, cancel)
+
+			_, err = client.AddIssue(cancelCtx, "title", "description")
+			Expect(errors.Is(err, context.Canceled)).To(BeTrue())
+		})
+
+		It("should be honoured by the memory tracker", func() {
+			cancelCtx, cancel := context.WithCancel(ctx)
+			cancel()
+
+			_, err := gitclient.NewMemoryTracker().AddIssue(cancelCtx, "title", "description")
+			Expect(errors.Is(err, context.Canceled)).To(BeTrue())
+		})
+	})
+})
@@ -0,0 +1,18 @@
+package gitclient
+
+import "context"
+
+// TokenSource supplies the token a GitClient authenticates with. It is asked
+// for a token before every request, so implementations may rotate tokens.
+type TokenSource interface {
+	Token(ctx context.Context) (string, error)
+}
+
+// StaticToken is a TokenSource that always returns the same token, such as a
+// personal access token.
+type StaticToken string
+
+// Token returns t.
+func (t StaticToken) Token(context.Context) (string, error) {
+	return string(t), nil
+}
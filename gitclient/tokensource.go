@@ -0,0 +1,214 @@
+package gitclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// execTokenRefresh is how long before their expiry tokens returned by an exec
+// plugin are replaced.
+const execTokenRefresh = time.Minute
+
+// invalidator is implemented by TokenSources that cache tokens. GitClient
+// calls invalidate when GitHub rejects token, so that the next request gets a
+// fresh one.
+type invalidator interface {
+	invalidate(token string)
+}
+
+// rateLimitObserver is implemented by TokenSources that pick tokens by their
+// remaining quota. GitClient reports the rate limit of every response to it.
+type rateLimitObserver interface {
+	observeRateLimit(token string, rateLimit RateLimit)
+	observeRateLimitError(token string, err *RateLimitError)
+}
+
+// credentialNamer is implemented by TokenSources that can name the credential
+// a token belongs to. The name must stay the same when the token is rotated,
+// as it labels the rate limit metrics.
+type credentialNamer interface {
+	credentialName(token string) string
+}
+
+// credentialNameOf names the credential token from tokens belongs to.
+func credentialNameOf(tokens TokenSource, token string) string {
+	if namer, ok := tokens.(credentialNamer); ok {
+		return namer.credentialName(token)
+	}
+	return "static"
+}
+
+// EnvToken returns a TokenSource reading the token from the environment
+// variable name on every request.
+func EnvToken(name string) TokenSource {
+	return envToken(name)
+}
+
+type envToken string
+
+func (e envToken) Token(context.Context) (string, error) {
+	token := os.Getenv(string(e))
+	if token == "" {
+		return "", fmt.Errorf("no token: environment variable %s is not set", string(e))
+	}
+	return token, nil
+}
+
+func (e envToken) credentialName(string) string {
+	return "env:" + string(e)
+}
+
+// FileToken returns a TokenSource reading the token from the file at path. The
+// file is read again whenever it changes, which is how Kubernetes rotates
+// mounted Secrets, so a new token takes effect without a restart.
+func FileToken(path string) TokenSource {
+	return &fileToken{path: path}
+}
+
+type fileToken struct {
+	path string
+
+	mu      sync.Mutex
+	token   string
+	modTime time.Time
+	size    int64
+}
+
+func (f *fileToken) Token(context.Context) (string, error) {
+	// Secret volumes swap a symlink to update their files, which Stat
+	// follows.
+	info, err := os.Stat(f.path)
+	if err != nil {
+		return "", fmt.Errorf("reading token: %w", err)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.token != "" && info.ModTime().Equal(f.modTime) && info.Size() == f.size {
+		return f.token, nil
+	}
+	data, err := os.ReadFile(f.path)
+	if err != nil {
+		return "", fmt.Errorf("reading token: %w", err)
+	}
+	token := strings.TrimSpace(string(data))
+	if token == "" {
+		return "", fmt.Errorf("no token: %s is empty", f.path)
+	}
+	f.token, f.modTime, f.size = token, info.ModTime(), info.Size()
+	return f.token, nil
+}
+
+func (f *fileToken) credentialName(string) string {
+	return "file:" + f.path
+}
+
+// ExecConfig configures a credential plugin in the style of kubectl's exec
+// plugins. The command prints an ExecCredential to stdout:
+//
+//	{
+//	  "apiVersion": "client.authentication.k8s.io/v1",
+//	  "kind": "ExecCredential",
+//	  "status": {
+//	    "token": "ghp_...",
+//	    "expirationTimestamp": "2025-01-01T00:00:00Z"
+//	  }
+//	}
+type ExecConfig struct {
+	// Command is the plugin to run.
+	Command string `json:"command"`
+	// Args are passed to Command.
+	Args []string `json:"args,omitempty"`
+	// Env is added to the environment of Command.
+	Env []ExecEnvVar `json:"env,omitempty"`
+}
+
+// ExecEnvVar is an environment variable set for an exec plugin.
+type ExecEnvVar struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// execCredential is the output of an exec plugin.
+type execCredential struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Status     *struct {
+		Token               string     `json:"token"`
+		ExpirationTimestamp *time.Time `json:"expirationTimestamp,omitempty"`
+	} `json:"status"`
+}
+
+// execInfo is passed to exec plugins in KUBERNETES_EXEC_INFO.
+const execInfo = `{"apiVersion":"client.authentication.k8s.io/v1","kind":"ExecCredential","spec":{"interactive":false}}`
+
+// ExecToken returns a TokenSource running the plugin described by cfg. Tokens
+// are cached until shortly before their expirationTimestamp; tokens without
+// one are cached until GitHub rejects them.
+func ExecToken(cfg ExecConfig) TokenSource {
+	return &execToken{cfg: cfg}
+}
+
+type execToken struct {
+	cfg ExecConfig
+
+	mu        sync.Mutex
+	token     string
+	expiresAt time.Time
+}
+
+func (e *execToken) Token(ctx context.Context) (string, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.token != "" && (e.expiresAt.IsZero() || time.Until(e.expiresAt) > execTokenRefresh) {
+		return e.token, nil
+	}
+
+	cmd := exec.CommandContext(ctx, e.cfg.Command, e.cfg.Args...)
+	cmd.Env = append(os.Environ(), "KUBERNETES_EXEC_INFO="+execInfo)
+	for _, env := range e.cfg.Env {
+		cmd.Env = append(cmd.Env, env.Name+"="+env.Value)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("running credential plugin %s: %w: %s", e.cfg.Command, err, strings.TrimSpace(stderr.String()))
+	}
+
+	var cred execCredential
+	if err := json.Unmarshal(stdout.Bytes(), &cred); err != nil {
+		return "", fmt.Errorf("decoding output of credential plugin %s: %w", e.cfg.Command, err)
+	}
+	if cred.Kind != "ExecCredential" || cred.Status == nil || cred.Status.Token == "" {
+		return "", fmt.Errorf("credential plugin %s returned no token", e.cfg.Command)
+	}
+
+	e.token = cred.Status.Token
+	e.expiresAt = time.Time{}
+	if cred.Status.ExpirationTimestamp != nil {
+		e.expiresAt = *cred.Status.ExpirationTimestamp
+	}
+	return e.token, nil
+}
+
+func (e *execToken) credentialName(string) string {
+	return "exec:" + e.cfg.Command
+}
+
+func (e *execToken) invalidate(token string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.token == token {
+		e.token = ""
+	}
+}
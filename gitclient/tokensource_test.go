@@ -0,0 +1,180 @@
+package gitclient_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/zszabo-rh/issues-operator/gitclient"
+)
+
+var _ = Describe("Token sources", func() {
+
+	Context("when reading the environment", func() {
+		It("should pick up a changed variable on the next request", func() {
+			GinkgoT().Setenv("ISSUES_OPERATOR_TEST_TOKEN", "first")
+			tokens := gitclient.EnvToken("ISSUES_OPERATOR_TEST_TOKEN")
+			Expect(tokens.Token(ctx)).To(Equal("first"))
+
+			GinkgoT().Setenv("ISSUES_OPERATOR_TEST_TOKEN", "second")
+			Expect(tokens.Token(ctx)).To(Equal("second"))
+		})
+
+		It("should fail when the variable is not set", func() {
+			_, err := gitclient.EnvToken("ISSUES_OPERATOR_UNSET_TOKEN").Token(ctx)
+			Expect(err).To(MatchError(ContainSubstring("ISSUES_OPERATOR_UNSET_TOKEN is not set")))
+		})
+	})
+
+	Context("when reading a file", func() {
+		It("should reload the token when the file changes", func() {
+			path := filepath.Join(GinkgoT().TempDir(), "token")
+			Expect(os.WriteFile(path, []byte("first\n"), 0o600)).To(Succeed())
+			tokens := gitclient.FileToken(path)
+			Expect(tokens.Token(ctx)).To(Equal("first"))
+
+			Expect(os.WriteFile(path, []byte("rotated\n"), 0o600)).To(Succeed())
+			later := time.Now().Add(time.Minute)
+			Expect(os.Chtimes(path, later, later)).To(Succeed())
+			Expect(tokens.Token(ctx)).To(Equal("rotated"))
+		})
+
+		It("should fail when the file is missing", func() {
+			_, err := gitclient.FileToken(filepath.Join(GinkgoT().TempDir(), "missing")).Token(ctx)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("when running a credential plugin", func() {
+		var (
+			dir    string
+			plugin string
+		)
+
+		// writePlugin installs a plugin printing token-<n>, where n counts
+		// its invocations, expiring after expiresIn.
+		writePlugin := func(expiresIn time.Duration) {
+			expiry := ""
+			if expiresIn != 0 {
+				expiry = fmt.Sprintf(`, "expirationTimestamp": "%s"`, time.Now().Add(expiresIn).UTC().Format(time.RFC3339))
+			}
+			script := fmt.Sprintf(`#!/bin/sh
+echo x >> %[1]s/calls
+n=$(wc -l < %[1]s/calls | tr -d ' ')
+echo '{"apiVersion": "client.authentication.k8s.io/v1", "kind": "ExecCredential", "status": {"token": "'"$PLUGIN_PREFIX"'-'$n'"%[2]s}}'
+`, dir, expiry)
+			Expect(os.WriteFile(plugin, []byte(script), 0o700)).To(Succeed())
+		}
+
+		BeforeEach(func() {
+			dir = GinkgoT().TempDir()
+			plugin = filepath.Join(dir, "plugin")
+		})
+
+		It("should cache the token until shortly before it expires", func() {
+			writePlugin(time.Hour)
+			tokens := gitclient.ExecToken(gitclient.ExecConfig{
+				Command: plugin,
+				Env:     []gitclient.ExecEnvVar{{Name: "PLUGIN_PREFIX", Value: "token"}},
+			})
+			Expect(tokens.Token(ctx)).To(Equal("token-1"))
+			Expect(tokens.Token(ctx)).To(Equal("token-1"))
+
+			writePlugin(30 * time.Second)
+			tokens = gitclient.ExecToken(gitclient.ExecConfig{
+				Command: plugin,
+				Env:     []gitclient.ExecEnvVar{{Name: "PLUGIN_PREFIX", Value: "token"}},
+			})
+			Expect(tokens.Token(ctx)).To(Equal("token-2"))
+			Expect(tokens.Token(ctx)).To(Equal("token-3"))
+		})
+
+		It("should run the plugin again when GitHub rejects a token without expiry", func() {
+			writePlugin(0)
+			var authorizations []string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				authorizations = append(authorizations, r.Header.Get("Authorization"))
+				if len(authorizations) == 1 {
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+				fmt.Fprint(w, `{"number": 1}`)
+			}))
+			defer server.Close()
+
+			tokens := gitclient.ExecToken(gitclient.ExecConfig{
+				Command: plugin,
+				Env:     []gitclient.ExecEnvVar{{Name: "PLUGIN_PREFIX", Value: "token"}},
+			})
+			client, err := gitclient.NewGitClient("myuser/myrepo", gitclient.WithBaseURL(server.URL), gitclient.WithTokenSource(tokens))
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = client.GetIssue(ctx, 1)
+			Expect(gitclient.IsUnauthorized(err)).To(BeTrue())
+			_, err = client.GetIssue(ctx, 1)
+			Expect(err).ToNot(HaveOccurred())
+			_, err = client.GetIssue(ctx, 1)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(authorizations).To(Equal([]string{"Bearer token-1", "Bearer token-2", "Bearer token-2"}))
+		})
+
+		It("should run the plugin again when GitHub rejects a pooled token without expiry", func() {
+			writePlugin(0)
+			var authorizations []string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				authorizations = append(authorizations, r.Header.Get("Authorization"))
+				if len(authorizations) == 1 {
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+				fmt.Fprint(w, `{"number": 1}`)
+			}))
+			defer server.Close()
+
+			pool := gitclient.NewTokenPool(gitclient.ExecToken(gitclient.ExecConfig{
+				Command: plugin,
+				Env:     []gitclient.ExecEnvVar{{Name: "PLUGIN_PREFIX", Value: "pooled"}},
+			}))
+			client, err := gitclient.NewGitClient("myuser/myrepo", gitclient.WithBaseURL(server.URL), gitclient.WithTokenSource(pool))
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = client.GetIssue(ctx, 1)
+			Expect(gitclient.IsUnauthorized(err)).To(BeTrue())
+			_, err = client.GetIssue(ctx, 1)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(authorizations).To(Equal([]string{"Bearer pooled-1", "Bearer pooled-2"}))
+		})
+
+		It("should report a failing plugin", func() {
+			Expect(os.WriteFile(plugin, []byte("#!/bin/sh\necho denied >&2\nexit 1\n"), 0o700)).To(Succeed())
+			_, err := gitclient.ExecToken(gitclient.ExecConfig{Command: plugin}).Token(ctx)
+			Expect(err).To(MatchError(ContainSubstring("denied")))
+		})
+	})
+
+	Context("when configured on a host", func() {
+		It("should prefer the exec plugin over the file and environment", func() {
+			dir := GinkgoT().TempDir()
+			plugin := filepath.Join(dir, "plugin")
+			Expect(os.WriteFile(plugin, []byte(`#!/bin/sh
+echo '{"kind": "ExecCredential", "status": {"token": "from-plugin"}}'
+`), 0o700)).To(Succeed())
+
+			hosts, err := gitclient.NewHosts(gitclient.HostConfig{
+				Host:      "github.example.com",
+				TokenFile: filepath.Join(dir, "unused"),
+				TokenExec: &gitclient.ExecConfig{Command: plugin},
+			})
+			Expect(err).ToNot(HaveOccurred())
+			tokens, err := hosts.TokenSource("github.example.com")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(tokens.Token(ctx)).To(Equal("from-plugin"))
+		})
+	})
+})
@@ -0,0 +1,49 @@
+package gitclient
+
+import "context"
+
+// IssueTracker is the set of operations the operator needs from a remote
+// issue tracker. GitClient implements it against the GitHub REST API and
+// MemoryTracker implements it in memory.
+type IssueTracker interface {
+	GetIssues(ctx context.Context, opts ListOptions) ([]GitIssue, error)
+	IterateIssues(ctx context.Context, opts ListOptions, fn func(GitIssue) bool) error
+	GetIssue(ctx context.Context, Id int) (GitIssue, error)
+	AddIssue(ctx context.Context, title string, desc string) (GitIssue, error)
+	UpdateIssue(ctx context.Context, Id int, title string, desc string) (GitIssue, error)
+	CloseIssue(ctx context.Context, Id int) (GitIssue, error)
+	EditIssue(ctx context.Context, Id int, edit IssueEdit) (GitIssue, error)
+	AddComment(ctx context.Context, Id int, body string) (GitComment, error)
+	LockIssue(ctx context.Context, Id int, reason string) error
+	GetLabel(ctx context.Context, name string) (GitLabel, error)
+	CreateLabel(ctx context.Context, label GitLabel) (GitLabel, error)
+	IsAssignable(ctx context.Context, login string) (bool, error)
+	ListMilestones(ctx context.Context) ([]GitMilestone, error)
+	CreateMilestone(ctx context.Context, milestone GitMilestone) (GitMilestone, error)
+}
+
+// TrackerFactory returns the IssueTracker responsible for a repository. opts
+// are applied on top of the factory's own options, e.g. to authenticate with
+// the credentials of a single resource.
+type TrackerFactory func(ref RepoRef, opts ...Option) (IssueTracker, error)
+
+var _ IssueTracker = &GitClient{}
+var _ IssueTracker = &MemoryTracker{}
+
+// NewGitHubTracker is the TrackerFactory used in production. It talks to the
+// GitHub API with the token from the environment.
+func NewGitHubTracker(ref RepoRef, opts ...Option) (IssueTracker, error) {
+	return NewGitHubTrackerFactory()(ref, opts...)
+}
+
+// NewGitHubTrackerFactory returns a TrackerFactory that creates GitClients
+// configured with opts.
+func NewGitHubTrackerFactory(opts ...Option) TrackerFactory {
+	return func(ref RepoRef, extra ...Option) (IssueTracker, error) {
+		client, err := NewRepoClient(ref, append(opts[:len(opts):len(opts)], extra...)...)
+		if err != nil {
+			return nil, err
+		}
+		return client, nil
+	}
+}
@@ -0,0 +1,91 @@
+package gitclient
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+
+	"golang.org/x/net/http/httpproxy"
+)
+
+// defaultHTTPClient is shared by GitClients created without WithHTTPClient,
+// so that they reuse connections to GitHub across reconciles.
+var defaultHTTPClient = &http.Client{Transport: mustNewTransport(TransportConfig{})}
+
+// TransportConfig configures the HTTP transport GitClients use to reach
+// GitHub.
+type TransportConfig struct {
+	// CAFile is a PEM bundle of additional certificate authorities to trust,
+	// for example the CA of an egress proxy mounted from a Secret.
+	CAFile string
+	// CAData is a PEM bundle trusted in addition to CAFile.
+	CAData []byte
+	// MaxIdleConnsPerHost is the number of keep-alive connections kept open
+	// to each host. It defaults to 10.
+	MaxIdleConnsPerHost int
+}
+
+// NewTransport returns an http.Transport that goes through the proxy named by
+// HTTPS_PROXY, HTTP_PROXY and NO_PROXY, trusts the system certificate pool plus
+// the CAs in cfg, and keeps connections alive between requests.
+func NewTransport(cfg TransportConfig) (*http.Transport, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	// The environment is read here rather than once per process, as
+	// http.ProxyFromEnvironment does, so that every transport sees the
+	// current settings.
+	proxyFunc := httpproxy.FromEnvironment().ProxyFunc()
+	transport.Proxy = func(req *http.Request) (*url.URL, error) {
+		return proxyFunc(req.URL)
+	}
+
+	transport.MaxIdleConnsPerHost = 10
+	if cfg.MaxIdleConnsPerHost > 0 {
+		transport.MaxIdleConnsPerHost = cfg.MaxIdleConnsPerHost
+	}
+
+	if cfg.CAFile != "" || len(cfg.CAData) > 0 {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if cfg.CAFile != "" {
+			pem, err := os.ReadFile(cfg.CAFile)
+			if err != nil {
+				return nil, fmt.Errorf("reading CA bundle: %w", err)
+			}
+			if !pool.AppendCertsFromPEM(pem) {
+				return nil, fmt.Errorf("no certificates found in CA bundle %s", cfg.CAFile)
+			}
+		}
+		if len(cfg.CAData) > 0 && !pool.AppendCertsFromPEM(cfg.CAData) {
+			return nil, fmt.Errorf("no certificates found in CA data")
+		}
+		transport.TLSClientConfig = &tls.Config{
+			RootCAs:    pool,
+			MinVersion: tls.VersionTLS12,
+		}
+	}
+	return transport, nil
+}
+
+// NewHTTPClient returns an http.Client using a transport built from cfg. The
+// client is meant to be created once and shared through WithHTTPClient.
+func NewHTTPClient(cfg TransportConfig) (*http.Client, error) {
+	transport, err := NewTransport(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &http.Client{Transport: transport}, nil
+}
+
+func mustNewTransport(cfg TransportConfig) *http.Transport {
+	transport, err := NewTransport(cfg)
+	if err != nil {
+		panic(err)
+	}
+	return transport
+}
@@ -0,0 +1,144 @@
+package gitclient_test
+
+import (
+	"encoding/pem"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/zszabo-rh/issues-operator/gitclient"
+)
+
+var _ = Describe("Transport", func() {
+
+	issueHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"number":1,"title":"issue","state":"open"}`)
+	})
+
+	Context("when GitHub is reached through a TLS-intercepting proxy", func() {
+		var server *httptest.Server
+
+		BeforeEach(func() {
+			server = httptest.NewTLSServer(issueHandler)
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		It("should trust the CA from the configured file", func() {
+			caFile := filepath.Join(GinkgoT().TempDir(), "ca.crt")
+			caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+			Expect(os.WriteFile(caFile, caPEM, 0o600)).To(Succeed())
+
+			httpClient, err := gitclient.NewHTTPClient(gitclient.TransportConfig{CAFile: caFile})
+			Expect(err).ToNot(HaveOccurred())
+			client, err := gitclient.NewGitClient("git@github.com:myrepo/myuser.git",
+				gitclient.WithBaseURL(server.URL), gitclient.WithToken("abc123"), gitclient.WithHTTPClient(httpClient))
+			Expect(err).ToNot(HaveOccurred())
+
+			gitissue, err := client.GetIssue(ctx, 1)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(gitissue.Title).To(Equal("issue"))
+		})
+
+		It("should reject the server without the CA", func() {
+			client, err := gitclient.NewGitClient("git@github.com:myrepo/myuser.git",
+				gitclient.WithBaseURL(server.URL), gitclient.WithToken("abc123"))
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = client.GetIssue(ctx, 1)
+			Expect(err).To(MatchError(ContainSubstring("certificate")))
+		})
+
+		It("should refuse a CA bundle without certificates", func() {
+			_, err := gitclient.NewHTTPClient(gitclient.TransportConfig{CAData: []byte("not a certificate")})
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("when HTTP_PROXY is set", func() {
+		var (
+			proxy      *httptest.Server
+			proxiedFor string
+		)
+
+		BeforeEach(func() {
+			proxy = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				proxiedFor = r.Host
+				issueHandler(w, r)
+			}))
+			for _, name := range []string{"HTTP_PROXY", "http_proxy", "NO_PROXY", "no_proxy"} {
+				original, ok := os.LookupEnv(name)
+				os.Unsetenv(name)
+				DeferCleanup(func() {
+					if ok {
+						os.Setenv(name, original)
+					}
+				})
+			}
+			os.Setenv("HTTP_PROXY", proxy.URL)
+		})
+
+		AfterEach(func() {
+			proxy.Close()
+		})
+
+		It("should send requests through the proxy", func() {
+			httpClient, err := gitclient.NewHTTPClient(gitclient.TransportConfig{})
+			Expect(err).ToNot(HaveOccurred())
+			client, err := gitclient.NewGitClient("git@github.com:myrepo/myuser.git",
+				gitclient.WithBaseURL("http://github.example.com/api/v3"), gitclient.WithToken("abc123"),
+				gitclient.WithHTTPClient(httpClient))
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = client.GetIssue(ctx, 1)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(proxiedFor).To(Equal("github.example.com"))
+		})
+
+		It("should bypass the proxy for hosts in NO_PROXY", func() {
+			os.Setenv("NO_PROXY", "github.example.com")
+			transport, err := gitclient.NewTransport(gitclient.TransportConfig{})
+			Expect(err).ToNot(HaveOccurred())
+
+			req, err := http.NewRequest("GET", "http://github.example.com/api/v3", nil)
+			Expect(err).ToNot(HaveOccurred())
+			proxyURL, err := transport.Proxy(req)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(proxyURL).To(BeNil())
+		})
+	})
+
+	Context("when several clients share an HTTP client", func() {
+		It("should reuse the keep-alive connection", func() {
+			var connections atomic.Int32
+			server := httptest.NewUnstartedServer(issueHandler)
+			server.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+				if state == http.StateNew {
+					connections.Add(1)
+				}
+			}
+			server.Start()
+			defer server.Close()
+
+			httpClient, err := gitclient.NewHTTPClient(gitclient.TransportConfig{})
+			Expect(err).ToNot(HaveOccurred())
+			for i := 0; i < 3; i++ {
+				client, err := gitclient.NewGitClient("git@github.com:myrepo/myuser.git",
+					gitclient.WithBaseURL(server.URL), gitclient.WithToken("abc123"), gitclient.WithHTTPClient(httpClient))
+				Expect(err).ToNot(HaveOccurred())
+				_, err = client.GetIssue(ctx, 1)
+				Expect(err).ToNot(HaveOccurred())
+			}
+			Expect(connections.Load()).To(Equal(int32(1)))
+		})
+	})
+})
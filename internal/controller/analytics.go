@@ -0,0 +1,176 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	trainingv1alpha1 "github.com/zszabo-rh/issues-operator/api/v1alpha1"
+	"github.com/zszabo-rh/issues-operator/gitclient"
+)
+
+var (
+	managedIssuesDesc = prometheus.NewDesc(
+		"issues_operator_managed_issues",
+		"GithubIssues bound to an issue on GitHub, by namespace, repository and state of the issue.",
+		[]string{"namespace", "repository", "state"}, nil)
+
+	managedIssueLabelsDesc = prometheus.NewDesc(
+		"issues_operator_managed_issue_labels",
+		"GithubIssues whose issue carries each label, by namespace, repository and state of the issue.",
+		[]string{"namespace", "repository", "label", "state"}, nil)
+
+	oldestOpenIssueAgeDesc = prometheus.NewDesc(
+		"issues_operator_oldest_open_issue_age_seconds",
+		"Age of the oldest open issue bound to a GithubIssue, by repository.",
+		[]string{"repository"}, nil)
+
+	timeToCloseDesc = prometheus.NewDesc(
+		"issues_operator_issue_time_to_close_seconds",
+		"Time from opening to closing of the closed issues bound to a GithubIssue, by repository.",
+		[]string{"repository"}, nil)
+)
+
+// timeToCloseBuckets range from an hour to a quarter.
+var timeToCloseBuckets = []float64{
+	(1 * time.Hour).Seconds(),
+	(6 * time.Hour).Seconds(),
+	(24 * time.Hour).Seconds(),
+	(3 * 24 * time.Hour).Seconds(),
+	(7 * 24 * time.Hour).Seconds(),
+	(14 * 24 * time.Hour).Seconds(),
+	(30 * 24 * time.Hour).Seconds(),
+	(90 * 24 * time.Hour).Seconds(),
+}
+
+// analyticsTimeout bounds listing the GithubIssues on each scrape.
+const analyticsTimeout = 10 * time.Second
+
+// IssueCollector exports metrics about the issues managed by the operator,
+// computed from the status of the GithubIssues on every scrape. Resources
+// that are not bound to an issue yet are left out.
+type IssueCollector struct {
+	Reader client.Reader
+
+	// now returns the current time. Tests replace it.
+	now func() time.Time
+}
+
+// NewIssueCollector returns a collector reading GithubIssues through reader,
+// usually the manager's cached client.
+func NewIssueCollector(reader client.Reader) *IssueCollector {
+	return &IssueCollector{Reader: reader, now: time.Now}
+}
+
+// Describe implements prometheus.Collector.
+func (c *IssueCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- managedIssuesDesc
+	ch <- managedIssueLabelsDesc
+	ch <- oldestOpenIssueAgeDesc
+	ch <- timeToCloseDesc
+}
+
+// issueCount is the key of the issue and label counts.
+type issueCount struct {
+	namespace, repository, label, state string
+}
+
+// closeTimes accumulates the time-to-close histogram of a repository.
+type closeTimes struct {
+	count   uint64
+	sum     float64
+	buckets map[float64]uint64
+}
+
+// Collect implements prometheus.Collector.
+func (c *IssueCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), analyticsTimeout)
+	defer cancel()
+
+	var list trainingv1alpha1.GithubIssueList
+	if err := c.Reader.List(ctx, &list); err != nil {
+		logf.Log.WithName("analytics").Error(err, "Failed to list GithubIssues")
+		return
+	}
+
+	now := c.now()
+	issues := map[issueCount]int{}
+	labels := map[issueCount]int{}
+	oldest := map[string]time.Time{}
+	closed := map[string]*closeTimes{}
+	for _, res := range list.Items {
+		if res.Status.IssueNumber == 0 || res.Status.State == "" {
+			continue
+		}
+		repository := res.Spec.Repository
+		if ref, err := gitclient.ParseRepo(res.Spec.Repository); err == nil {
+			repository = ref.String()
+		}
+		state := res.Status.State
+
+		issues[issueCount{namespace: res.Namespace, repository: repository, state: state}]++
+		for _, label := range res.Status.Labels {
+			labels[issueCount{namespace: res.Namespace, repository: repository, label: label, state: state}]++
+		}
+
+		created := res.Status.CreatedAt
+		if created == nil {
+			continue
+		}
+		switch state {
+		case gitclient.StateOpen:
+			if first, ok := oldest[repository]; !ok || created.Time.Before(first) {
+				oldest[repository] = created.Time
+			}
+		case gitclient.StateClosed:
+			if res.Status.ClosedAt == nil {
+				continue
+			}
+			times := closed[repository]
+			if times == nil {
+				times = &closeTimes{buckets: map[float64]uint64{}}
+				closed[repository] = times
+			}
+			elapsed := max(res.Status.ClosedAt.Sub(created.Time), 0).Seconds()
+			times.count++
+			times.sum += elapsed
+			for _, bound := range timeToCloseBuckets {
+				if elapsed <= bound {
+					times.buckets[bound]++
+				}
+			}
+		}
+	}
+
+	for key, n := range issues {
+		ch <- prometheus.MustNewConstMetric(managedIssuesDesc, prometheus.GaugeValue, float64(n), key.namespace, key.repository, key.state)
+	}
+	for key, n := range labels {
+		ch <- prometheus.MustNewConstMetric(managedIssueLabelsDesc, prometheus.GaugeValue, float64(n), key.namespace, key.repository, key.label, key.state)
+	}
+	for repository, created := range oldest {
+		ch <- prometheus.MustNewConstMetric(oldestOpenIssueAgeDesc, prometheus.GaugeValue, max(now.Sub(created), 0).Seconds(), repository)
+	}
+	for repository, times := range closed {
+		ch <- prometheus.MustNewConstHistogram(timeToCloseDesc, times.count, times.sum, times.buckets, repository)
+	}
+}
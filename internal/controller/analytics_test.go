@@ -0,0 +1,151 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	trainingv1alpha1 "github.com/zszabo-rh/issues-operator/api/v1alpha1"
+)
+
+var _ = Describe("Issue analytics", func() {
+	ctx := context.Background()
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	// The issues are filed into a repository of their own, so that
+	// resources left behind by other tests do not show up.
+	const repository = "github.com/myrepo/analytics"
+
+	var created []*trainingv1alpha1.GithubIssue
+
+	create := func(name string, status trainingv1alpha1.GithubIssueStatus) {
+		res := &trainingv1alpha1.GithubIssue{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec: trainingv1alpha1.GithubIssueSpec{
+				Repository: "git@github.com:myrepo/analytics.git",
+				Title:      name,
+			},
+		}
+		Expect(k8sClient.Create(ctx, res)).To(Succeed())
+		res.Status = status
+		Expect(k8sClient.Status().Update(ctx, res)).To(Succeed())
+		created = append(created, res)
+	}
+
+	at := func(ago time.Duration) *metav1.Time {
+		t := metav1.NewTime(now.Add(-ago))
+		return &t
+	}
+
+	BeforeEach(func() {
+		created = nil
+		create("analytics-old", trainingv1alpha1.GithubIssueStatus{
+			IssueNumber: 1, State: "open", CreatedAt: at(48 * time.Hour), Labels: []string{"bug"},
+		})
+		create("analytics-new", trainingv1alpha1.GithubIssueStatus{
+			IssueNumber: 2, State: "open", CreatedAt: at(time.Hour), Labels: []string{"bug", "docs"},
+		})
+		create("analytics-closed", trainingv1alpha1.GithubIssueStatus{
+			IssueNumber: 3, State: "closed", CreatedAt: at(72 * time.Hour), ClosedAt: at(70 * time.Hour),
+		})
+		create("analytics-pending", trainingv1alpha1.GithubIssueStatus{})
+	})
+
+	AfterEach(func() {
+		for _, res := range created {
+			Expect(k8sClient.Delete(ctx, res)).To(Succeed())
+		}
+	})
+
+	// collect gathers the metrics of the analytics repository by family.
+	collect := func() map[string][]*dto.Metric {
+		collector := NewIssueCollector(k8sClient)
+		collector.now = func() time.Time { return now }
+		registry := prometheus.NewPedanticRegistry()
+		Expect(registry.Register(collector)).To(Succeed())
+
+		families, err := registry.Gather()
+		Expect(err).NotTo(HaveOccurred())
+		metrics := map[string][]*dto.Metric{}
+		for _, family := range families {
+			for _, metric := range family.GetMetric() {
+				for _, label := range metric.GetLabel() {
+					if label.GetName() == "repository" && label.GetValue() == repository {
+						metrics[family.GetName()] = append(metrics[family.GetName()], metric)
+					}
+				}
+			}
+		}
+		return metrics
+	}
+
+	labelsOf := func(metric *dto.Metric) map[string]string {
+		labels := map[string]string{}
+		for _, label := range metric.GetLabel() {
+			labels[label.GetName()] = label.GetValue()
+		}
+		return labels
+	}
+
+	It("should count bound issues by namespace, repository and state", func() {
+		counts := map[string]float64{}
+		for _, metric := range collect()["issues_operator_managed_issues"] {
+			labels := labelsOf(metric)
+			Expect(labels).To(HaveKeyWithValue("namespace", "default"))
+			counts[labels["state"]] = metric.GetGauge().GetValue()
+		}
+		Expect(counts).To(Equal(map[string]float64{"open": 2, "closed": 1}))
+	})
+
+	It("should count issues by label", func() {
+		counts := map[string]float64{}
+		for _, metric := range collect()["issues_operator_managed_issue_labels"] {
+			labels := labelsOf(metric)
+			Expect(labels).To(HaveKeyWithValue("state", "open"))
+			counts[labels["label"]] = metric.GetGauge().GetValue()
+		}
+		Expect(counts).To(Equal(map[string]float64{"bug": 2, "docs": 1}))
+	})
+
+	It("should report the age of the oldest open issue", func() {
+		ages := collect()["issues_operator_oldest_open_issue_age_seconds"]
+		Expect(ages).To(HaveLen(1))
+		Expect(ages[0].GetGauge().GetValue()).To(Equal((48 * time.Hour).Seconds()))
+	})
+
+	It("should report the time it took to close issues", func() {
+		histograms := collect()["issues_operator_issue_time_to_close_seconds"]
+		Expect(histograms).To(HaveLen(1))
+		histogram := histograms[0].GetHistogram()
+		Expect(histogram.GetSampleCount()).To(BeEquivalentTo(1))
+		Expect(histogram.GetSampleSum()).To(Equal((2 * time.Hour).Seconds()))
+		for _, bucket := range histogram.GetBucket() {
+			if bucket.GetUpperBound() < (6 * time.Hour).Seconds() {
+				Expect(bucket.GetCumulativeCount()).To(BeZero())
+			} else {
+				Expect(bucket.GetCumulativeCount()).To(BeEquivalentTo(1))
+			}
+		}
+	})
+})
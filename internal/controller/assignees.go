@@ -0,0 +1,162 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	trainingv1alpha1 "github.com/zszabo-rh/issues-operator/api/v1alpha1"
+	"github.com/zszabo-rh/issues-operator/gitclient"
+)
+
+// checkAssignees returns the GitHub logins for spec.assignees split into
+// those issues in the repository can be assigned to and those they cannot.
+// Logins existing is already assigned to are not checked again.
+func (r *GithubIssueReconciler) checkAssignees(ctx context.Context, client gitclient.IssueTracker, res *trainingv1alpha1.GithubIssue, existing gitclient.GitIssue) ([]string, []string, error) {
+	if len(res.Spec.Assignees) == 0 {
+		return nil, nil, nil
+	}
+	identities, err := r.identities(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	current := newNameSet(userLogins(existing.Assignees)...)
+	var assignable, unassignable []string
+	for _, assignee := range res.Spec.Assignees {
+		login := assignee
+		if mapped, ok := identities[assignee]; ok {
+			login = mapped
+		}
+		ok := current.Has(login)
+		if !ok {
+			ok, err = client.IsAssignable(ctx, login)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+		if ok {
+			assignable = append(assignable, login)
+		} else {
+			unassignable = append(unassignable, login)
+		}
+	}
+	return assignable, unassignable, nil
+}
+
+// identities returns the GitHub logins of Kubernetes users from the identity
+// map. Each key of the ConfigMap is a GitHub login, and its value lists the
+// Kubernetes user names of that user separated by whitespace.
+func (r *GithubIssueReconciler) identities(ctx context.Context) (map[string]string, error) {
+	if r.IdentityMap.Name == "" {
+		return nil, nil
+	}
+
+	configMap := &corev1.ConfigMap{}
+	err := r.Get(ctx, r.IdentityMap, configMap)
+	if errors.IsNotFound(err) {
+		log.FromContext(ctx).Info("Identity map not found, using assignees as GitHub logins", "configMap", r.IdentityMap.String())
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	identities := map[string]string{}
+	for login, users := range configMap.Data {
+		for _, user := range strings.Fields(users) {
+			identities[user] = login
+		}
+	}
+	return identities, nil
+}
+
+// desiredAssignees returns the logins existing should be assigned to, or nil
+// if its assignees are up to date. want holds the assignable logins from
+// spec.assignees. Like labels, only assignees the operator added are removed.
+func desiredAssignees(res *trainingv1alpha1.GithubIssue, existing gitclient.GitIssue, want gitclient.GitIssue) []string {
+	return syncNames(userLogins(existing.Assignees), userLogins(want.Assignees), res.Status.ManagedAssignees)
+}
+
+// managedAssignees returns the logins the operator manages once existing is
+// assigned to the logins from desiredAssignees.
+func managedAssignees(res *trainingv1alpha1.GithubIssue, existing gitclient.GitIssue, want gitclient.GitIssue) []string {
+	return managedNames(userLogins(existing.Assignees), userLogins(want.Assignees), res.Status.ManagedAssignees)
+}
+
+// setAssigneesCondition records on res which of its assignees cannot be
+// assigned the issue.
+func setAssigneesCondition(res *trainingv1alpha1.GithubIssue, unassignable []string) {
+	if len(res.Spec.Assignees) == 0 {
+		meta.RemoveStatusCondition(&res.Status.Conditions, trainingv1alpha1.ConditionAssigneesValid)
+		return
+	}
+
+	if len(unassignable) > 0 {
+		setCondition(res, trainingv1alpha1.ConditionAssigneesValid, metav1.ConditionFalse, "Unassignable",
+			"cannot assign issues in this repository to "+strings.Join(unassignable, ", "))
+		return
+	}
+	setCondition(res, trainingv1alpha1.ConditionAssigneesValid, metav1.ConditionTrue, "Assignable", "All assignees can be assigned the issue")
+}
+
+func userLogins(users []gitclient.GitUser) []string {
+	logins := make([]string, 0, len(users))
+	for _, user := range users {
+		logins = append(logins, user.Login)
+	}
+	return logins
+}
+
+func gitUsers(logins []string) []gitclient.GitUser {
+	users := make([]gitclient.GitUser, 0, len(logins))
+	for _, login := range logins {
+		users = append(users, gitclient.GitUser{Login: login})
+	}
+	return users
+}
+
+// issuesForIdentityMap maps the identity map to the GithubIssues with
+// assignees, so that changing it re-syncs them.
+func (r *GithubIssueReconciler) issuesForIdentityMap(ctx context.Context, obj client.Object) []reconcile.Request {
+	if client.ObjectKeyFromObject(obj) != r.IdentityMap {
+		return nil
+	}
+
+	issues := &trainingv1alpha1.GithubIssueList{}
+	if err := r.List(ctx, issues); err != nil {
+		log.FromContext(ctx).Error(err, "unable to list GithubIssues for the identity map")
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, issue := range issues.Items {
+		if len(issue.Spec.Assignees) > 0 {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&issue)})
+		}
+	}
+	return requests
+}
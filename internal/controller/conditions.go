@@ -0,0 +1,111 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	trainingv1alpha1 "github.com/zszabo-rh/issues-operator/api/v1alpha1"
+	"github.com/zszabo-rh/issues-operator/gitclient"
+)
+
+// setCondition sets the condition of the given type on res for its current
+// generation.
+func setCondition(res *trainingv1alpha1.GithubIssue, conditionType string, status metav1.ConditionStatus, reason, message string) {
+	meta.SetStatusCondition(&res.Status.Conditions, metav1.Condition{
+		Type:               conditionType,
+		Status:             status,
+		ObservedGeneration: res.Generation,
+		Reason:             reason,
+		Message:            message,
+	})
+}
+
+// setRemoteConditions records what the outcome of a call to GitHub says about
+// the credentials and the repository of res and the rate limit. A nil err
+// marks all of them as fine.
+func setRemoteConditions(res *trainingv1alpha1.GithubIssue, err error) {
+	if retryAt, ok := gitclient.RetryAt(err); ok {
+		setCondition(res, trainingv1alpha1.ConditionRateLimited, metav1.ConditionTrue, "RateLimitExceeded",
+			fmt.Sprintf("GitHub rate limit exceeded until %s", retryAt.UTC().Format(time.RFC3339)))
+		return
+	}
+	setCondition(res, trainingv1alpha1.ConditionRateLimited, metav1.ConditionFalse, "WithinLimit", "GitHub accepted the last request")
+
+	switch {
+	case err == nil:
+		setCredentialsValid(res)
+		setCondition(res, trainingv1alpha1.ConditionRepositoryAccessible, metav1.ConditionTrue, "Accessible", "The repository is accessible")
+	case gitclient.IsUnauthorized(err):
+		setCondition(res, trainingv1alpha1.ConditionCredentialsValid, metav1.ConditionFalse, "Unauthorized", err.Error())
+	case gitclient.IsForbidden(err):
+		setCredentialsValid(res)
+		setCondition(res, trainingv1alpha1.ConditionRepositoryAccessible, metav1.ConditionFalse, "Forbidden", err.Error())
+	case gitclient.IsNotFound(err):
+		// GitHub also answers 404 for private repositories the
+		// credentials cannot see.
+		setCredentialsValid(res)
+		setCondition(res, trainingv1alpha1.ConditionRepositoryAccessible, metav1.ConditionFalse, "NotFound", err.Error())
+	}
+}
+
+func setCredentialsValid(res *trainingv1alpha1.GithubIssue) {
+	setCondition(res, trainingv1alpha1.ConditionCredentialsValid, metav1.ConditionTrue, "Authenticated", "GitHub accepted the credentials")
+}
+
+// setSynced records whether the issue of res matches its spec, and updates
+// the Ready condition to match.
+func setSynced(res *trainingv1alpha1.GithubIssue, synced bool, reason, message string) {
+	status := metav1.ConditionFalse
+	if synced {
+		status = metav1.ConditionTrue
+	}
+	setCondition(res, trainingv1alpha1.ConditionSynced, status, reason, message)
+	setReady(res)
+}
+
+// setReady summarizes the other conditions of res into its Ready condition.
+func setReady(res *trainingv1alpha1.GithubIssue) {
+	conditions := res.Status.Conditions
+	if condition := meta.FindStatusCondition(conditions, trainingv1alpha1.ConditionDeletionBlocked); condition != nil && condition.Status == metav1.ConditionTrue {
+		setCondition(res, trainingv1alpha1.ConditionReady, metav1.ConditionFalse, "DeletionBlocked", condition.Message)
+		return
+	}
+	if condition := meta.FindStatusCondition(conditions, trainingv1alpha1.ConditionRateLimited); condition != nil && condition.Status == metav1.ConditionTrue {
+		setCondition(res, trainingv1alpha1.ConditionReady, metav1.ConditionFalse, "RateLimited", condition.Message)
+		return
+	}
+	for _, conditionType := range []string{
+		trainingv1alpha1.ConditionCredentialsValid,
+		trainingv1alpha1.ConditionRepositoryAccessible,
+		trainingv1alpha1.ConditionSynced,
+	} {
+		if condition := meta.FindStatusCondition(conditions, conditionType); condition != nil && condition.Status != metav1.ConditionTrue {
+			setCondition(res, trainingv1alpha1.ConditionReady, metav1.ConditionFalse, condition.Reason, condition.Message)
+			return
+		}
+	}
+	if !meta.IsStatusConditionTrue(conditions, trainingv1alpha1.ConditionSynced) {
+		setCondition(res, trainingv1alpha1.ConditionReady, metav1.ConditionFalse, "Reconciling", "The issue has not been synced yet")
+		return
+	}
+	setCondition(res, trainingv1alpha1.ConditionReady, metav1.ConditionTrue, "Synced", "The issue matches the spec")
+}
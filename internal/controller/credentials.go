@@ -0,0 +1,123 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	trainingv1alpha1 "github.com/zszabo-rh/issues-operator/api/v1alpha1"
+	"github.com/zszabo-rh/issues-operator/gitclient"
+)
+
+const (
+	// credentialsSecretIndex indexes GithubIssues by the name of the Secret
+	// their credentials are read from.
+	credentialsSecretIndex = ".spec.credentialsRef.name"
+
+	// defaultCredentialsKey is the key of the token in a credentials Secret.
+	defaultCredentialsKey = "token"
+)
+
+// credentialsError reports credentials that cannot be read until the user
+// fixes the Secret or the resource.
+type credentialsError struct {
+	message string
+}
+
+func (e *credentialsError) Error() string {
+	return e.message
+}
+
+// credentials returns the options authenticating the tracker of res with the
+// token from its credentials Secret, or no options when res uses the
+// operator's own credentials: when there is no default Secret, or when the
+// namespace lacks it and falling back is allowed.
+func (r *GithubIssueReconciler) credentials(ctx context.Context, res *trainingv1alpha1.GithubIssue) ([]gitclient.Option, error) {
+	name, key := r.DefaultCredentialsSecret, defaultCredentialsKey
+	if ref := res.Spec.CredentialsRef; ref != nil {
+		name = ref.Name
+		if ref.Key != "" {
+			key = ref.Key
+		}
+	}
+	if name == "" {
+		return nil, nil
+	}
+
+	secret := &corev1.Secret{}
+	err := r.Get(ctx, types.NamespacedName{Namespace: res.Namespace, Name: name}, secret)
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			return nil, err
+		}
+		if res.Spec.CredentialsRef == nil {
+			if r.FallbackToOperatorCredentials {
+				return nil, nil
+			}
+			return nil, &credentialsError{fmt.Sprintf("default credentials secret %s not found and spec.credentialsRef is not set", name)}
+		}
+		return nil, &credentialsError{fmt.Sprintf("credentials secret %s not found", name)}
+	}
+
+	token := strings.TrimSpace(string(secret.Data[key]))
+	if token == "" {
+		return nil, &credentialsError{fmt.Sprintf("credentials secret %s has no %q key", name, key)}
+	}
+	return []gitclient.Option{
+		gitclient.WithToken(token),
+		gitclient.WithCredentialName("secret:" + res.Namespace + "/" + name),
+	}, nil
+}
+
+// credentialsSecret returns the name of the Secret the credentials of obj are
+// read from, for indexing under credentialsSecretIndex.
+func (r *GithubIssueReconciler) credentialsSecret(obj client.Object) []string {
+	res := obj.(*trainingv1alpha1.GithubIssue)
+	if res.Spec.CredentialsRef != nil {
+		return []string{res.Spec.CredentialsRef.Name}
+	}
+	if r.DefaultCredentialsSecret != "" {
+		return []string{r.DefaultCredentialsSecret}
+	}
+	return nil
+}
+
+// issuesForSecret maps a Secret to the GithubIssues reading their credentials
+// from it, so that rotating a token re-syncs them.
+func (r *GithubIssueReconciler) issuesForSecret(ctx context.Context, obj client.Object) []reconcile.Request {
+	issues := &trainingv1alpha1.GithubIssueList{}
+	err := r.List(ctx, issues, client.InNamespace(obj.GetNamespace()), client.MatchingFields{credentialsSecretIndex: obj.GetName()})
+	if err != nil {
+		log.FromContext(ctx).Error(err, "unable to list GithubIssues using secret", "secret", obj.GetNamespace()+"/"+obj.GetName())
+		return nil
+	}
+
+	requests := make([]reconcile.Request, 0, len(issues.Items))
+	for _, issue := range issues.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&issue)})
+	}
+	return requests
+}
@@ -0,0 +1,141 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	trainingv1alpha1 "github.com/zszabo-rh/issues-operator/api/v1alpha1"
+	"github.com/zszabo-rh/issues-operator/gitclient"
+)
+
+// issueFinalizer keeps a GithubIssue around until its issue has been cleaned
+// up according to spec.deletionPolicy.
+const issueFinalizer = "training.redhat.com/issue-cleanup"
+
+// finalize cleans up the issue bound to res, which is being deleted and
+// needsCleanup, and then releases res. Failures block the deletion and are
+// retried until they succeed.
+func (r *GithubIssueReconciler) finalize(ctx context.Context, res *trainingv1alpha1.GithubIssue, client gitclient.IssueTracker, owner ownerMarker, readOnly string) (ctrl.Result, error) {
+	log := log.FromContext(ctx)
+
+	number := res.Status.IssueNumber
+	policy := res.Spec.DeletionPolicy
+	if readOnly != "" {
+		return r.blockDeletion(ctx, res, "ReadOnly", fmt.Errorf("cannot clean up issue #%d: %s", number, readOnly))
+	}
+
+	existing, err := client.GetIssue(ctx, number)
+	if gitclient.IsNotFound(err) {
+		log.Info("Bound github issue is gone", "number", number)
+		return r.removeFinalizer(ctx, res)
+	}
+	if err != nil {
+		return r.blockDeletion(ctx, res, "GetIssueFailed", err)
+	}
+	if marker, ok := parseOwnerMarker(existing.Description); ok && !owner.Owns(marker) {
+		log.Info("Leaving github issue owned by another resource", "number", number, "owner", marker.Resource())
+		return r.removeFinalizer(ctx, res)
+	}
+
+	switch policy {
+	case trainingv1alpha1.DeletionPolicyLock:
+		if !existing.Locked {
+			log.Info("Locking github issue", "number", number)
+			if err := client.LockIssue(ctx, number, "resolved"); err != nil {
+				return r.blockDeletion(ctx, res, "LockFailed", err)
+			}
+			r.event(res, corev1.EventTypeNormal, "Locked", fmt.Sprintf("Locked issue #%d: %s", number, issueURL(res, number)))
+		}
+	default:
+		// The issue is closed before the comment is posted, so that a
+		// failed close never leaves a comment behind to be posted again on
+		// the retry. A comment that failed after the close is retried on its
+		// own.
+		comment := isDeletionBlocked(res, "CommentFailed")
+		if existing.Status != gitclient.StateClosed {
+			log.Info("Closing github issue", "number", number)
+			edit := gitclient.IssueEdit{State: gitclient.StateClosed, StateReason: gitclient.StateReasonNotPlanned}
+			if _, err := client.EditIssue(ctx, number, edit); err != nil {
+				return r.blockDeletion(ctx, res, "CloseFailed", err)
+			}
+			countIssueChange(issuesClosed, res)
+			r.event(res, corev1.EventTypeNormal, "Closed", fmt.Sprintf("Closed issue #%d as %s: %s", number, gitclient.StateReasonNotPlanned, issueURL(res, number)))
+			comment = true
+		}
+		if comment {
+			body := fmt.Sprintf("Closing this issue because GithubIssue %s/%s was deleted.", res.Namespace, res.Name)
+			if _, err := client.AddComment(ctx, number, body); err != nil {
+				return r.blockDeletion(ctx, res, "CommentFailed", err)
+			}
+		}
+	}
+	return r.removeFinalizer(ctx, res)
+}
+
+// needsCleanup reports whether deleting res has to act on its issue. Issues
+// under the Orphan policy are left alone, and there is nothing to do if no
+// issue was ever bound.
+func needsCleanup(res *trainingv1alpha1.GithubIssue) bool {
+	return res.Status.IssueNumber != 0 && res.Spec.DeletionPolicy != trainingv1alpha1.DeletionPolicyOrphan
+}
+
+// blockDeletion records on res why its issue could not be cleaned up and
+// retries: after the rate limit resets for rate limit errors, and with
+// controller-runtime's backoff otherwise.
+func (r *GithubIssueReconciler) blockDeletion(ctx context.Context, res *trainingv1alpha1.GithubIssue, reason string, err error) (ctrl.Result, error) {
+	log.FromContext(ctx).Info("Deletion blocked", "reason", reason, "error", err.Error())
+
+	message := err.Error() + "; set spec.deletionPolicy to Orphan to delete the resource without cleaning up the issue"
+	setCondition(res, trainingv1alpha1.ConditionDeletionBlocked, metav1.ConditionTrue, reason, message)
+	setReady(res)
+	r.event(res, corev1.EventTypeWarning, "DeletionBlocked", fmt.Sprintf("%s: %s", message, issueURL(res, boundIssue(res))))
+	if updateErr := r.Status().Update(ctx, res); updateErr != nil {
+		return ctrl.Result{}, updateErr
+	}
+
+	if retryAt, ok := gitclient.RetryAt(err); ok {
+		return ctrl.Result{RequeueAfter: max(time.Until(retryAt), time.Second)}, nil
+	}
+	return ctrl.Result{}, err
+}
+
+// isDeletionBlocked reports whether the deletion of res was last blocked for
+// reason.
+func isDeletionBlocked(res *trainingv1alpha1.GithubIssue, reason string) bool {
+	condition := meta.FindStatusCondition(res.Status.Conditions, trainingv1alpha1.ConditionDeletionBlocked)
+	return condition != nil && condition.Status == metav1.ConditionTrue && condition.Reason == reason
+}
+
+// removeFinalizer lets the deletion of res complete.
+func (r *GithubIssueReconciler) removeFinalizer(ctx context.Context, res *trainingv1alpha1.GithubIssue) (ctrl.Result, error) {
+	if controllerutil.RemoveFinalizer(res, issueFinalizer) {
+		if err := r.Update(ctx, res); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	return ctrl.Result{}, nil
+}
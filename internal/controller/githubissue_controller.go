@@ -19,11 +19,20 @@ package controller
 import (
 	"context"
 	"fmt"
+	"strings"
+	"time"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 
@@ -35,11 +44,58 @@ import (
 type GithubIssueReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
+
+	// NewTracker builds the IssueTracker for a repository. It defaults to
+	// gitclient.NewGitHubTracker when left unset.
+	NewTracker gitclient.TrackerFactory
+
+	// ClusterID is recorded in the owner marker of every issue, so that
+	// operators in different clusters filing into the same repository do not
+	// claim each other's issues.
+	ClusterID string
+
+	// DefaultCredentialsSecret names the Secret holding the GitHub token for
+	// GithubIssues without spec.credentialsRef, looked up in their own
+	// namespace. All resources use the operator's own credentials when it
+	// is empty.
+	DefaultCredentialsSecret string
+
+	// FallbackToOperatorCredentials lets resources in namespaces without
+	// the DefaultCredentialsSecret use the operator's own credentials.
+	// Without it they are not synced until the Secret is created, so that
+	// no tenant can act as the operator's GitHub account.
+	FallbackToOperatorCredentials bool
+
+	// Recorder emits events on GithubIssues. Events are dropped when it is
+	// nil.
+	Recorder record.EventRecorder
+
+	// Credentials reports the state of the operator's own credentials. It
+	// may be nil, e.g. with the in-memory tracker.
+	Credentials *CredentialsMonitor
+
+	// ReadOnly keeps the reconciler from creating or editing issues. Bound
+	// issues are still read to report their state, every retryPeriod.
+	ReadOnly bool
+
+	// ResyncPeriod is how often synced issues are checked for changes made
+	// on GitHub. Zero disables resyncing.
+	ResyncPeriod time.Duration
+
+	// IdentityMap names a ConfigMap mapping GitHub logins to Kubernetes
+	// user names, which may then be used in spec.assignees. It is unused
+	// when its name is empty.
+	IdentityMap types.NamespacedName
+
+	milestones milestoneCache
 }
 
 // +kubebuilder:rbac:groups=training.redhat.com,resources=githubissues,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=training.redhat.com,resources=githubissues/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=training.redhat.com,resources=githubissues/finalizers,verbs=update
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -59,55 +115,316 @@ func (r *GithubIssueReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 
 	if err != nil {
 		if errors.IsNotFound(err) {
-			log.Error(err, "Issue not found!")
+			log.Info("GithubIssue is gone, nothing to do")
 			return ctrl.Result{}, nil
 		}
 		return ctrl.Result{}, err
 	}
 
+	// The finalizer goes on before any issue is filed, so that every issue
+	// is cleaned up when the resource is deleted.
+	deleting := !githubissue.DeletionTimestamp.IsZero()
+	if !deleting && controllerutil.AddFinalizer(githubissue, issueFinalizer) {
+		if err := r.Update(ctx, githubissue); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	// Releasing a resource that leaves GitHub alone must not depend on its
+	// credentials, which are often deleted along with it.
+	if deleting && !needsCleanup(githubissue) {
+		log.Info("Leaving github issue behind", "number", githubissue.Status.IssueNumber, "policy", githubissue.Spec.DeletionPolicy)
+		return r.removeFinalizer(ctx, githubissue)
+	}
+
 	clientissue := gitclient.GitIssue{}
 	repo := githubissue.Spec.Repository
 	clientissue.Title = githubissue.Spec.Title
-	clientissue.Description = githubissue.Spec.Description
+	owner := ownerMarkerFor(githubissue, r.ClusterID)
+	clientissue.Description = withOwnerMarker(githubissue.Spec.Description, owner)
 
-	client, err := gitclient.NewGitClient(repo)
+	ref, err := gitclient.ParseRepo(repo)
 	if err != nil {
+		if deleting {
+			// No issue can have been filed into an invalid repository.
+			return r.removeFinalizer(ctx, githubissue)
+		}
+		log.Info("Invalid repository, waiting for the resource to change", "error", err.Error())
+		setCondition(githubissue, trainingv1alpha1.ConditionRepositoryAccessible, metav1.ConditionFalse, "InvalidRepository", err.Error())
+		return r.UpdateMessage(ctx, githubissue, "InvalidRepository", err.Error())
+	}
+
+	// Credentials are resolved on every reconcile, so that rotated tokens
+	// take effect without restarting the operator.
+	credentials, err := r.credentials(ctx, githubissue)
+	if err != nil {
+		if deleting {
+			return r.blockDeletion(ctx, githubissue, "CredentialsUnavailable", err)
+		}
+		if _, ok := err.(*credentialsError); ok {
+			log.Info("Invalid credentials, waiting for the secret to change", "error", err.Error())
+			setCondition(githubissue, trainingv1alpha1.ConditionCredentialsValid, metav1.ConditionFalse, "SecretInvalid", err.Error())
+			return r.UpdateMessage(ctx, githubissue, "SecretInvalid", err.Error())
+		}
 		return ctrl.Result{}, err
 	}
 
-	issues, err := client.GetIssues()
+	client, err := r.tracker(ref, credentials...)
 	if err != nil {
+		if deleting {
+			return r.blockDeletion(ctx, githubissue, "TrackerUnavailable", err)
+		}
 		return ctrl.Result{}, err
 	}
 
-	found := false
-	for _, issue := range issues {
-		if issue.Title == clientissue.Title {
-			log.Info("Match! Updating description")
-			found = true
-			updatedissue, err := client.UpdateIssue(issue.Id, clientissue.Title, clientissue.Description)
+	// The operator's own credentials are checked by r.Credentials;
+	// credentials from Secrets are the users' to look after.
+	readOnly := ""
+	if r.ReadOnly {
+		readOnly = "the operator runs in read-only mode"
+	}
+	if len(credentials) == 0 && r.Credentials != nil {
+		for _, warning := range r.Credentials.Warnings(ref.Host) {
+			r.event(githubissue, corev1.EventTypeWarning, "CredentialsExpiring", warning)
+		}
+		if reason := r.Credentials.ReadOnly(ref.Host); reason != "" && readOnly == "" {
+			readOnly = reason
+		}
+	}
+
+	if deleting {
+		return r.finalize(ctx, githubissue, client, owner, readOnly)
+	}
+
+	// A GithubIssue stays bound to the issue it created or adopted, so that
+	// renaming it edits the same issue. spec.issueNumber takes precedence to
+	// let users adopt an existing issue.
+	number := githubissue.Spec.IssueNumber
+	if number == 0 {
+		number = githubissue.Status.IssueNumber
+	}
+
+	// A previous reconcile may have created the issue and failed before
+	// recording its number. Look for our marker before creating another one.
+	if number == 0 {
+		number, err = findOwnedIssue(ctx, client, owner)
+		if err != nil {
+			return r.remoteError(ctx, githubissue, err)
+		}
+		if number != 0 {
+			log.Info("Found issue carrying our owner marker", "number", number)
+		}
+	}
+
+	if number != 0 {
+		existing, err := client.GetIssue(ctx, number)
+		if err != nil {
+			log.Error(err, "GetIssue("+repo+", "+fmt.Sprint(number)+") failed")
+			return r.remoteError(ctx, githubissue, err)
+		}
+		if marker, ok := parseOwnerMarker(existing.Description); ok && !owner.Owns(marker) {
+			gone, err := r.ownerGone(ctx, marker)
 			if err != nil {
-				log.Error(err, "UpdateIssue("+repo+", "+fmt.Sprintf("%v", clientissue)+") failed")
 				return ctrl.Result{}, err
 			}
+			if !gone {
+				message := fmt.Sprintf("issue #%d is owned by %s", number, marker.Resource())
+				log.Info("Refusing to update issue owned by another resource", "number", number, "owner", marker.Resource())
+				return r.UpdateConflict(ctx, githubissue, message)
+			}
+			log.Info("Taking over issue whose owner no longer exists", "number", number, "owner", marker.Resource())
+		}
+		if readOnly != "" {
+			log.Info("Not updating bound github issue", "number", number, "reason", readOnly)
+			return r.UpdateReadOnly(ctx, githubissue, &existing, readOnly)
+		}
+		if number != githubissue.Status.IssueNumber {
+			r.event(githubissue, corev1.EventTypeNormal, "Adopted", fmt.Sprintf("Adopted issue #%d: %s", number, issueURL(githubissue, number)))
+		}
 
-			return r.UpdateResource(ctx, githubissue, updatedissue)
+		edit, synced, err := r.plan(ctx, client, ref, githubissue, existing, clientissue)
+		if err != nil {
+			return r.remoteError(ctx, githubissue, err)
+		}
+		if edit == (gitclient.IssueEdit{}) {
+			log.Info("Bound github issue is up to date", "number", number)
+			return r.UpdateResource(ctx, githubissue, existing, synced)
 		}
-	}
 
-	if !found {
-		log.Info("No issues matched! Creating new github issue")
-		newissue, err := client.AddIssue(clientissue.Title, clientissue.Description)
+		log.Info("Updating bound github issue", "number", number, "state", edit.State)
+		updatedissue, err := r.editIssue(ctx, client, ref, githubissue, existing, edit)
 		if err != nil {
-			log.Error(err, "AddIssue("+repo+", "+fmt.Sprintf("%v", clientissue)+") failed")
-			return ctrl.Result{}, err
+			log.Error(err, "EditIssue("+repo+", "+fmt.Sprintf("%v", edit)+") failed")
+			return r.remoteError(ctx, githubissue, err)
 		}
-		return r.UpdateResource(ctx, githubissue, newissue)
+		r.editEvents(githubissue, number, edit)
+		return r.UpdateResource(ctx, githubissue, updatedissue, synced)
 	}
-	return ctrl.Result{}, nil
+
+	if readOnly != "" {
+		log.Info("Not creating github issue", "reason", readOnly)
+		return r.UpdateReadOnly(ctx, githubissue, nil, readOnly)
+	}
+
+	log.Info("No issue bound yet! Creating new github issue")
+	newissue, err := client.AddIssue(ctx, clientissue.Title, clientissue.Description)
+	if err != nil {
+		log.Error(err, "AddIssue("+repo+", "+fmt.Sprintf("%v", clientissue)+") failed")
+		return r.remoteError(ctx, githubissue, err)
+	}
+	countIssueChange(issuesCreated, githubissue)
+	r.event(githubissue, corev1.EventTypeNormal, "Created", fmt.Sprintf("Created issue #%d: %s", newissue.Id, issueURL(githubissue, newissue.Id)))
+
+	// Issues are always opened without labels and assignees; close, label
+	// and assign right away if that is what the spec asks for. The new issue
+	// is bound first if that fails, so that the next reconcile retries on it
+	// rather than opening another one.
+	edit, synced, err := r.plan(ctx, client, ref, githubissue, newissue, clientissue)
+	if err == nil && edit != (gitclient.IssueEdit{}) {
+		log.Info("Updating new github issue", "number", newissue.Id, "state", edit.State)
+		var editedissue gitclient.GitIssue
+		editedissue, err = r.editIssue(ctx, client, ref, githubissue, newissue, edit)
+		if err == nil {
+			r.editEvents(githubissue, newissue.Id, edit)
+			newissue = editedissue
+		}
+	}
+	if err != nil {
+		githubissue.Status.IssueNumber = newissue.Id
+		if updateErr := r.Status().Update(ctx, githubissue); updateErr != nil {
+			return ctrl.Result{}, updateErr
+		}
+		return r.remoteError(ctx, githubissue, err)
+	}
+	return r.UpdateResource(ctx, githubissue, newissue, synced)
 }
 
-func (r *GithubIssueReconciler) UpdateResource(ctx context.Context, res *trainingv1alpha1.GithubIssue, issue gitclient.GitIssue) (ctrl.Result, error) {
+// plan returns the edit that brings existing in line with the spec of res,
+// with want holding the desired title and body, and what to record on res
+// once it is applied. It resolves the milestone and checks the assignees the
+// spec asks for.
+func (r *GithubIssueReconciler) plan(ctx context.Context, client gitclient.IssueTracker, ref gitclient.RepoRef, res *trainingv1alpha1.GithubIssue, existing gitclient.GitIssue, want gitclient.GitIssue) (gitclient.IssueEdit, syncResult, error) {
+	milestone, err := r.resolveMilestone(ctx, client, ref, res)
+	if err != nil {
+		return gitclient.IssueEdit{}, syncResult{}, err
+	}
+	want.Milestone = milestone
+
+	assignable, unassignable, err := r.checkAssignees(ctx, client, res, existing)
+	if err != nil {
+		return gitclient.IssueEdit{}, syncResult{}, err
+	}
+	if len(unassignable) > 0 {
+		r.event(res, corev1.EventTypeWarning, "Unassignable", fmt.Sprintf("Cannot assign issues in this repository to %s: %s", strings.Join(unassignable, ", "), issueURL(res, existing.Id)))
+	}
+	want.Assignees = gitUsers(assignable)
+
+	synced := syncResult{
+		managedLabels:    managedLabels(res, existing),
+		managedAssignees: managedAssignees(res, existing, want),
+		unassignable:     unassignable,
+	}
+	return desiredEdit(res, existing, want), synced, nil
+}
+
+// syncResult is what syncing an issue learned beyond the issue itself.
+type syncResult struct {
+	managedLabels    []string
+	managedAssignees []string
+	// unassignable lists the logins from spec.assignees that cannot be
+	// assigned issues in the repository.
+	unassignable []string
+}
+
+// editIssue applies edit to existing, creating the labels it adds first.
+func (r *GithubIssueReconciler) editIssue(ctx context.Context, client gitclient.IssueTracker, ref gitclient.RepoRef, res *trainingv1alpha1.GithubIssue, existing gitclient.GitIssue, edit gitclient.IssueEdit) (gitclient.GitIssue, error) {
+	if edit.Labels != nil {
+		if err := ensureLabels(ctx, client, res, existing); err != nil {
+			return gitclient.GitIssue{}, err
+		}
+	}
+	issue, err := client.EditIssue(ctx, existing.Id, edit)
+	if err != nil && edit.Milestone != 0 && gitclient.IsValidation(err) {
+		// The cached milestone may have been deleted. Retry with a fresh
+		// list rather than waiting for the resource to change.
+		r.milestones.forget(ref.String())
+		return gitclient.GitIssue{}, fmt.Errorf("adding issue to milestone #%d: %s", edit.Milestone, err)
+	}
+	return issue, err
+}
+
+// remoteError returns the result for a failed issue tracker call. Rate limit
+// errors requeue the request for when the limit resets instead of going
+// through controller-runtime's exponential backoff, which would only keep
+// hitting the limit. Errors that retrying cannot fix are recorded on the
+// resource and wait for its spec to change.
+func (r *GithubIssueReconciler) remoteError(ctx context.Context, res *trainingv1alpha1.GithubIssue, err error) (ctrl.Result, error) {
+	log := log.FromContext(ctx)
+	url := issueURL(res, boundIssue(res))
+
+	setRemoteConditions(res, err)
+	if retryAt, ok := gitclient.RetryAt(err); ok {
+		requeueAfter := time.Until(retryAt)
+		if requeueAfter < time.Second {
+			requeueAfter = time.Second
+		}
+		log.Info("GitHub rate limit exceeded, requeueing", "retryAt", retryAt, "requeueAfter", requeueAfter)
+		r.event(res, corev1.EventTypeWarning, "RateLimited", fmt.Sprintf("GitHub rate limit exceeded, retrying at %s: %s", retryAt.UTC().Format(time.RFC3339), url))
+		if _, updateErr := r.UpdateMessage(ctx, res, "RateLimited", err.Error()); updateErr != nil {
+			return ctrl.Result{}, updateErr
+		}
+		return ctrl.Result{RequeueAfter: requeueAfter}, nil
+	}
+
+	if _, ok := err.(*milestoneError); ok {
+		log.Info("Milestone not found, waiting for it to be created", "error", err.Error())
+		r.event(res, corev1.EventTypeWarning, "MilestoneNotFound", fmt.Sprintf("%s: %s", err, url))
+		if _, updateErr := r.UpdateMessage(ctx, res, "MilestoneNotFound", err.Error()); updateErr != nil {
+			return ctrl.Result{}, updateErr
+		}
+		// The milestone may still be created on GitHub.
+		return ctrl.Result{RequeueAfter: r.retryPeriod()}, nil
+	}
+	if gitclient.IsValidation(err) {
+		log.Info("GitHub rejected the request, waiting for the resource to change", "error", err.Error())
+		r.event(res, corev1.EventTypeWarning, "ValidationFailed", fmt.Sprintf("GitHub rejected the request: %s: %s", err, url))
+		return r.UpdateMessage(ctx, res, "Rejected", err.Error())
+	}
+	r.event(res, corev1.EventTypeWarning, "APIError", fmt.Sprintf("GitHub request failed: %s: %s", err, url))
+	if gitclient.IsNotFound(err) {
+		log.Info("GitHub rejected the request, waiting for the resource to change", "error", err.Error())
+		return r.UpdateMessage(ctx, res, "NotFound", err.Error())
+	}
+	if _, updateErr := r.UpdateMessage(ctx, res, "SyncFailed", err.Error()); updateErr != nil {
+		return ctrl.Result{}, updateErr
+	}
+	return ctrl.Result{}, err
+}
+
+func (r *GithubIssueReconciler) tracker(ref gitclient.RepoRef, opts ...gitclient.Option) (gitclient.IssueTracker, error) {
+	if r.NewTracker == nil {
+		return gitclient.NewGitHubTracker(ref, opts...)
+	}
+	return r.NewTracker(ref, opts...)
+}
+
+// findOwnedIssue returns the number of the issue carrying owner's marker, or 0
+// if there is none.
+func findOwnedIssue(ctx context.Context, client gitclient.IssueTracker, owner ownerMarker) (int, error) {
+	number := 0
+	opts := gitclient.ListOptions{State: "all", PerPage: 100}
+	err := client.IterateIssues(ctx, opts, func(issue gitclient.GitIssue) bool {
+		if marker, ok := parseOwnerMarker(issue.Description); ok && owner.Owns(marker) {
+			number = issue.Id
+			return false
+		}
+		return true
+	})
+	return number, err
+}
+
+// UpdateResource records issue in the status of res, along with what syncing
+// it learned.
+func (r *GithubIssueReconciler) UpdateResource(ctx context.Context, res *trainingv1alpha1.GithubIssue, issue gitclient.GitIssue, synced syncResult) (ctrl.Result, error) {
 	log := log.FromContext(ctx)
 	log.Info("Updating spec")
 	err := r.Update(ctx, res)
@@ -115,21 +432,138 @@ func (r *GithubIssueReconciler) UpdateResource(ctx context.Context, res *trainin
 		return ctrl.Result{}, err
 	}
 
-	res.Status.State = issue.Status
-	res.Status.LastUpdated = issue.LastUpdated
+	recordIssue(res, issue)
+	res.Status.ObservedGeneration = res.Generation
+	res.Status.ManagedLabels = synced.managedLabels
+	res.Status.ManagedAssignees = synced.managedAssignees
+	setAssigneesCondition(res, synced.unassignable)
+	res.Status.Message = ""
+	setRemoteConditions(res, nil)
+	setSynced(res, true, "Synced", fmt.Sprintf("Issue #%d matches the spec", issue.Id))
 
-	log.Info("Updating status: " + res.Status.State + ", " + res.Status.LastUpdated)
+	log.Info("Updating status: "+res.Status.State, "lastUpdated", issue.LastUpdated)
 	err = r.Status().Update(ctx, res)
 	if err != nil {
 		return ctrl.Result{}, err
 	}
+	return ctrl.Result{RequeueAfter: r.ResyncPeriod}, nil
+}
+
+// UpdateConflict records on res that its issue is owned by another resource.
+// The issue itself and status.state, which only ever holds the state of the
+// issue on GitHub, are left untouched.
+func (r *GithubIssueReconciler) UpdateConflict(ctx context.Context, res *trainingv1alpha1.GithubIssue, message string) (ctrl.Result, error) {
+	setRemoteConditions(res, nil)
+	r.event(res, corev1.EventTypeWarning, "Conflict", fmt.Sprintf("Not updating %s: %s", issueURL(res, boundIssue(res)), message))
+	return r.UpdateMessage(ctx, res, "Conflict", message)
+}
+
+// UpdateReadOnly records on res that its issue was not changed because the
+// operator may not write to the repository. The state of the bound issue, if
+// any, is still reported.
+func (r *GithubIssueReconciler) UpdateReadOnly(ctx context.Context, res *trainingv1alpha1.GithubIssue, issue *gitclient.GitIssue, reason string) (ctrl.Result, error) {
+	if issue != nil {
+		recordIssue(res, *issue)
+		setRemoteConditions(res, nil)
+	}
+	r.event(res, corev1.EventTypeWarning, "ReadOnly", fmt.Sprintf("Issue not synced: %s: %s", reason, issueURL(res, boundIssue(res))))
+	if _, err := r.UpdateMessage(ctx, res, "ReadOnly", "issue not synced: "+reason); err != nil {
+		return ctrl.Result{}, err
+	}
+	// Nothing in the cluster changes when the credentials regain write
+	// access, so check again later.
+	return ctrl.Result{RequeueAfter: r.retryPeriod()}, nil
+}
+
+// retryPeriod is how long resources waiting for a change on GitHub, such as
+// write access or a missing milestone, wait before they are reconciled
+// again: the resync period, or the credentials check interval without one.
+func (r *GithubIssueReconciler) retryPeriod() time.Duration {
+	if r.ResyncPeriod > 0 {
+		return r.ResyncPeriod
+	}
+	if r.Credentials != nil && r.Credentials.Interval > 0 {
+		return r.Credentials.Interval
+	}
+	return DefaultCredentialsCheckInterval
+}
+
+// editEvents records the changes edit made to issue number of res as events
+// and in the issue metrics.
+func (r *GithubIssueReconciler) editEvents(res *trainingv1alpha1.GithubIssue, number int, edit gitclient.IssueEdit) {
+	url := issueURL(res, number)
+	switch edit.State {
+	case gitclient.StateClosed:
+		countIssueChange(issuesClosed, res)
+		r.event(res, corev1.EventTypeNormal, "Closed", fmt.Sprintf("Closed issue #%d as %s: %s", number, edit.StateReason, url))
+	case gitclient.StateOpen:
+		countIssueChange(issuesUpdated, res)
+		r.event(res, corev1.EventTypeNormal, "Reopened", fmt.Sprintf("Reopened issue #%d: %s", number, url))
+	}
+
+	var changed []string
+	if edit.Title != "" {
+		changed = append(changed, "title")
+	}
+	if edit.Description != "" {
+		changed = append(changed, "description")
+	}
+	if edit.Labels != nil {
+		changed = append(changed, "labels")
+	}
+	if edit.Assignees != nil {
+		changed = append(changed, "assignees")
+	}
+	if edit.Milestone != 0 {
+		changed = append(changed, "milestone")
+	}
+	if len(changed) > 0 {
+		if edit.State != gitclient.StateOpen {
+			countIssueChange(issuesUpdated, res)
+		}
+		r.event(res, corev1.EventTypeNormal, "Updated", fmt.Sprintf("Updated %s of issue #%d: %s", strings.Join(changed, ", "), number, url))
+	}
+}
+
+// event records an event on obj if r has a Recorder.
+func (r *GithubIssueReconciler) event(obj runtime.Object, eventtype, reason, message string) {
+	if r.Recorder != nil {
+		r.Recorder.Event(obj, eventtype, reason, message)
+	}
+}
+
+// UpdateMessage records on res why it could not be synced, both in its
+// message and in its Synced condition.
+func (r *GithubIssueReconciler) UpdateMessage(ctx context.Context, res *trainingv1alpha1.GithubIssue, reason, message string) (ctrl.Result, error) {
+	res.Status.Message = message
+	setSynced(res, false, reason, message)
+
+	err := r.Status().Update(ctx, res)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
 	return ctrl.Result{}, nil
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *GithubIssueReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
-		For(&trainingv1alpha1.GithubIssue{}).
-		WithEventFilter(predicate.GenerationChangedPredicate{}).
-		Complete(r)
+	err := mgr.GetFieldIndexer().IndexField(context.Background(), &trainingv1alpha1.GithubIssue{}, credentialsSecretIndex, r.credentialsSecret)
+	if err != nil {
+		return err
+	}
+
+	b := ctrl.NewControllerManagedBy(mgr).
+		For(&trainingv1alpha1.GithubIssue{}, builder.WithPredicates(predicate.Or(
+			predicate.GenerationChangedPredicate{},
+			// Deleting a resource with finalizers only sets its deletion
+			// timestamp, which must still trigger the cleanup.
+			predicate.NewPredicateFuncs(func(obj client.Object) bool {
+				return !obj.GetDeletionTimestamp().IsZero()
+			}),
+		))).
+		Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(r.issuesForSecret))
+	if r.IdentityMap.Name != "" {
+		b = b.Watches(&corev1.ConfigMap{}, handler.EnqueueRequestsFromMapFunc(r.issuesForIdentityMap))
+	}
+	return b.Complete(r)
 }
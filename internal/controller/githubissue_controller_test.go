@@ -18,18 +18,31 @@ package controller
 
 import (
 	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	trainingv1alpha1 "github.com/zszabo-rh/issues-operator/api/v1alpha1"
+	"github.com/zszabo-rh/issues-operator/gitclient"
 )
 
+// testRepo is the repository the test resources file their issues into.
+var testRepo = gitclient.RepoRef{Host: "github.com", Owner: "myrepo", Name: "myuser"}
+
 var _ = Describe("GithubIssue Controller", func() {
 	Context("When reconciling a resource", func() {
 		const resourceName = "test-resource"
@@ -51,7 +64,11 @@ var _ = Describe("GithubIssue Controller", func() {
 						Name:      resourceName,
 						Namespace: "default",
 					},
-					// TODO(user): Specify other spec details if needed.
+					Spec: trainingv1alpha1.GithubIssueSpec{
+						Repository:  "git@github.com:myrepo/myuser.git",
+						Title:       "test issue",
+						Description: "test description",
+					},
 				}
 				Expect(k8sClient.Create(ctx, resource)).To(Succeed())
 			}
@@ -64,21 +81,1284 @@ var _ = Describe("GithubIssue Controller", func() {
 			Expect(err).NotTo(HaveOccurred())
 
 			By("Cleanup the specific resource instance GithubIssue")
-			Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+			resource.Finalizers = nil
+			Expect(k8sClient.Update(ctx, resource)).To(Succeed())
+			Expect(client.IgnoreNotFound(k8sClient.Delete(ctx, resource))).To(Succeed())
 		})
+
+		// memoryReconciler returns a reconciler filing issues in a fresh
+		// in-memory tracker, along with the tracker of the test repository.
+		memoryReconciler := func() (*GithubIssueReconciler, *gitclient.MemoryTracker) {
+			newTracker := gitclient.NewMemoryTrackerFactory()
+			tracker, err := newTracker(testRepo)
+			Expect(err).NotTo(HaveOccurred())
+			reconciler := &GithubIssueReconciler{
+				Client:     k8sClient,
+				Scheme:     k8sClient.Scheme(),
+				NewTracker: newTracker,
+			}
+			return reconciler, tracker.(*gitclient.MemoryTracker)
+		}
+
+		updateSpec := func(mutate func(*trainingv1alpha1.GithubIssueSpec)) {
+			resource := &trainingv1alpha1.GithubIssue{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			mutate(&resource.Spec)
+			Expect(k8sClient.Update(ctx, resource)).To(Succeed())
+		}
+
+		// reconcileAndFetch reconciles the test resource and returns it along
+		// with the issue it is bound to in tracker.
+		reconcileAndFetch := func(reconciler *GithubIssueReconciler, tracker gitclient.IssueTracker) (*trainingv1alpha1.GithubIssue, gitclient.GitIssue) {
+			_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			resource := &trainingv1alpha1.GithubIssue{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			issue, err := tracker.GetIssue(ctx, resource.Status.IssueNumber)
+			Expect(err).NotTo(HaveOccurred())
+			return resource, issue
+		}
+
 		It("should successfully reconcile the resource", func() {
 			By("Reconciling the created resource")
+			newTracker := gitclient.NewMemoryTrackerFactory()
 			controllerReconciler := &GithubIssueReconciler{
-				Client: k8sClient,
-				Scheme: k8sClient.Scheme(),
+				Client:     k8sClient,
+				Scheme:     k8sClient.Scheme(),
+				NewTracker: newTracker,
 			}
 
 			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
 				NamespacedName: typeNamespacedName,
 			})
 			Expect(err).NotTo(HaveOccurred())
-			// TODO(user): Add more specific assertions depending on your controller's reconciliation logic.
-			// Example: If you expect a certain status condition after reconciliation, verify it here.
+
+			By("Checking that the issue was filed and the status updated")
+			tracker, err := newTracker(testRepo)
+			Expect(err).NotTo(HaveOccurred())
+			gitissues, err := tracker.GetIssues(ctx, gitclient.ListOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(gitissues).To(HaveLen(1))
+			Expect(gitissues[0].Title).To(Equal("test issue"))
+
+			resource := &trainingv1alpha1.GithubIssue{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			Expect(resource.Status.State).To(Equal("open"))
+			Expect(resource.Status.IssueNumber).To(Equal(gitissues[0].Id))
+		})
+
+		It("should edit the bound issue when the title changes", func() {
+			newTracker := gitclient.NewMemoryTrackerFactory()
+			controllerReconciler := &GithubIssueReconciler{
+				Client:     k8sClient,
+				Scheme:     k8sClient.Scheme(),
+				NewTracker: newTracker,
+			}
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Renaming the resource")
+			resource := &trainingv1alpha1.GithubIssue{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			resource.Spec.Title = "renamed issue"
+			Expect(k8sClient.Update(ctx, resource)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			tracker, err := newTracker(testRepo)
+			Expect(err).NotTo(HaveOccurred())
+			gitissues, err := tracker.GetIssues(ctx, gitclient.ListOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(gitissues).To(HaveLen(1))
+			Expect(gitissues[0].Title).To(Equal("renamed issue"))
+		})
+
+		It("should adopt the issue given in spec.issueNumber", func() {
+			newTracker := gitclient.NewMemoryTrackerFactory()
+			tracker, err := newTracker(testRepo)
+			Expect(err).NotTo(HaveOccurred())
+			_, err = tracker.AddIssue(ctx, "first", "unrelated")
+			Expect(err).NotTo(HaveOccurred())
+			existing, err := tracker.AddIssue(ctx, "existing issue", "filed by hand")
+			Expect(err).NotTo(HaveOccurred())
+
+			resource := &trainingv1alpha1.GithubIssue{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			resource.Spec.IssueNumber = existing.Id
+			Expect(k8sClient.Update(ctx, resource)).To(Succeed())
+
+			controllerReconciler := &GithubIssueReconciler{
+				Client:     k8sClient,
+				Scheme:     k8sClient.Scheme(),
+				NewTracker: newTracker,
+			}
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			gitissues, err := tracker.GetIssues(ctx, gitclient.ListOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(gitissues).To(HaveLen(2))
+			adopted, err := tracker.GetIssue(ctx, existing.Id)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(adopted.Title).To(Equal("test issue"))
+
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			Expect(resource.Status.IssueNumber).To(Equal(existing.Id))
+		})
+
+		It("should adopt an issue carrying its owner marker instead of creating another", func() {
+			resource := &trainingv1alpha1.GithubIssue{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+
+			newTracker := gitclient.NewMemoryTrackerFactory()
+			tracker, err := newTracker(testRepo)
+			Expect(err).NotTo(HaveOccurred())
+			orphan, err := tracker.AddIssue(ctx, "test issue", withOwnerMarker("test description", ownerMarkerFor(resource, "")))
+			Expect(err).NotTo(HaveOccurred())
+
+			controllerReconciler := &GithubIssueReconciler{
+				Client:     k8sClient,
+				Scheme:     k8sClient.Scheme(),
+				NewTracker: newTracker,
+			}
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			gitissues, err := tracker.GetIssues(ctx, gitclient.ListOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(gitissues).To(HaveLen(1))
+
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			Expect(resource.Status.IssueNumber).To(Equal(orphan.Id))
+		})
+
+		// createOwner creates another GithubIssue and returns the marker it
+		// puts on its issues.
+		createOwner := func(name string) ownerMarker {
+			other := &trainingv1alpha1.GithubIssue{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+				Spec:       trainingv1alpha1.GithubIssueSpec{Repository: "git@github.com:myrepo/myuser.git", Title: name},
+			}
+			Expect(k8sClient.Create(ctx, other)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(client.IgnoreNotFound(k8sClient.Delete(ctx, other))).To(Succeed())
+			})
+			return ownerMarkerFor(other, "")
+		}
+
+		It("should refuse to update an issue owned by another resource", func() {
+			newTracker := gitclient.NewMemoryTrackerFactory()
+			tracker, err := newTracker(testRepo)
+			Expect(err).NotTo(HaveOccurred())
+			foreign := createOwner("other")
+			owned, err := tracker.AddIssue(ctx, "owned elsewhere", withOwnerMarker("untouched", foreign))
+			Expect(err).NotTo(HaveOccurred())
+
+			resource := &trainingv1alpha1.GithubIssue{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			resource.Spec.IssueNumber = owned.Id
+			Expect(k8sClient.Update(ctx, resource)).To(Succeed())
+
+			controllerReconciler := &GithubIssueReconciler{
+				Client:     k8sClient,
+				Scheme:     k8sClient.Scheme(),
+				NewTracker: newTracker,
+			}
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			unchanged, err := tracker.GetIssue(ctx, owned.Id)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(unchanged.Title).To(Equal("owned elsewhere"))
+
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			Expect(resource.Status.State).To(BeEmpty())
+			Expect(resource.Status.Message).To(ContainSubstring("default/other"))
+			synced := meta.FindStatusCondition(resource.Status.Conditions, trainingv1alpha1.ConditionSynced)
+			Expect(synced).NotTo(BeNil())
+			Expect(synced.Status).To(Equal(metav1.ConditionFalse))
+			Expect(synced.Reason).To(Equal("Conflict"))
+		})
+
+		It("should take over an issue left behind by a deleted resource", func() {
+			newTracker := gitclient.NewMemoryTrackerFactory()
+			tracker, err := newTracker(testRepo)
+			Expect(err).NotTo(HaveOccurred())
+
+			resource := &trainingv1alpha1.GithubIssue{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			// The marker of an earlier resource under the same name, and of
+			// one that is gone altogether.
+			for _, orphaned := range []ownerMarker{
+				{UID: "deleted-uid", Namespace: "default", Name: resourceName},
+				{UID: "other-deleted-uid", Namespace: "default", Name: "deleted"},
+			} {
+				issue, err := tracker.AddIssue(ctx, "orphaned", withOwnerMarker("left behind", orphaned))
+				Expect(err).NotTo(HaveOccurred())
+				resource.Spec.IssueNumber = issue.Id
+				Expect(k8sClient.Update(ctx, resource)).To(Succeed())
+
+				controllerReconciler := &GithubIssueReconciler{
+					Client:     k8sClient,
+					Scheme:     k8sClient.Scheme(),
+					NewTracker: newTracker,
+				}
+				_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+				Expect(err).NotTo(HaveOccurred())
+
+				adopted, err := tracker.GetIssue(ctx, issue.Id)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(adopted.Title).To(Equal(resource.Spec.Title))
+				marker, ok := parseOwnerMarker(adopted.Description)
+				Expect(ok).To(BeTrue())
+				Expect(marker.UID).To(Equal(string(resource.UID)))
+
+				Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+				Expect(resource.Status.IssueNumber).To(Equal(issue.Id))
+			}
+		})
+
+		It("should not take over issues of resources in other clusters", func() {
+			newTracker := gitclient.NewMemoryTrackerFactory()
+			tracker, err := newTracker(testRepo)
+			Expect(err).NotTo(HaveOccurred())
+			foreign := ownerMarker{UID: "other-uid", Namespace: "default", Name: "other", ClusterID: "elsewhere"}
+			owned, err := tracker.AddIssue(ctx, "owned elsewhere", withOwnerMarker("untouched", foreign))
+			Expect(err).NotTo(HaveOccurred())
+
+			resource := &trainingv1alpha1.GithubIssue{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			resource.Spec.IssueNumber = owned.Id
+			Expect(k8sClient.Update(ctx, resource)).To(Succeed())
+
+			controllerReconciler := &GithubIssueReconciler{
+				Client:     k8sClient,
+				Scheme:     k8sClient.Scheme(),
+				NewTracker: newTracker,
+			}
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			Expect(resource.Status.IssueNumber).To(BeZero())
+			Expect(resource.Status.Message).To(ContainSubstring("in cluster elsewhere"))
+		})
+
+		It("should not record an issue owned by another resource in read-only mode", func() {
+			newTracker := gitclient.NewMemoryTrackerFactory()
+			tracker, err := newTracker(testRepo)
+			Expect(err).NotTo(HaveOccurred())
+			foreign := createOwner("other")
+			owned, err := tracker.AddIssue(ctx, "owned elsewhere", withOwnerMarker("untouched", foreign))
+			Expect(err).NotTo(HaveOccurred())
+
+			resource := &trainingv1alpha1.GithubIssue{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			resource.Spec.IssueNumber = owned.Id
+			Expect(k8sClient.Update(ctx, resource)).To(Succeed())
+
+			controllerReconciler := &GithubIssueReconciler{
+				Client:     k8sClient,
+				Scheme:     k8sClient.Scheme(),
+				NewTracker: newTracker,
+				ReadOnly:   true,
+			}
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			Expect(resource.Status.IssueNumber).To(BeZero())
+			Expect(resource.Status.HTMLURL).To(BeEmpty())
+			Expect(resource.Status.Message).To(ContainSubstring("default/other"))
+		})
+
+		It("should requeue at the reset time when rate limited", func() {
+			retryAt := time.Now().Add(15 * time.Minute)
+			controllerReconciler := &GithubIssueReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+				NewTracker: func(ref gitclient.RepoRef, opts ...gitclient.Option) (gitclient.IssueTracker, error) {
+					return &rateLimitedTracker{MemoryTracker: gitclient.NewMemoryTracker(), retryAt: retryAt}, nil
+				},
+			}
+
+			result, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(BeNumerically("~", 15*time.Minute, time.Minute))
+
+			resource := &trainingv1alpha1.GithubIssue{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			Expect(meta.IsStatusConditionTrue(resource.Status.Conditions, trainingv1alpha1.ConditionRateLimited)).To(BeTrue())
+			ready := meta.FindStatusCondition(resource.Status.Conditions, trainingv1alpha1.ConditionReady)
+			Expect(ready).NotTo(BeNil())
+			Expect(ready.Status).To(Equal(metav1.ConditionFalse))
+			Expect(ready.Reason).To(Equal("RateLimited"))
+		})
+
+		It("should record a missing issue on the status instead of retrying", func() {
+			resource := &trainingv1alpha1.GithubIssue{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			resource.Spec.IssueNumber = 42
+			Expect(k8sClient.Update(ctx, resource)).To(Succeed())
+
+			controllerReconciler := &GithubIssueReconciler{
+				Client:     k8sClient,
+				Scheme:     k8sClient.Scheme(),
+				NewTracker: gitclient.NewMemoryTrackerFactory(),
+			}
+			result, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Requeue).To(BeFalse())
+
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			Expect(resource.Status.Message).To(Equal("404 Not Found"))
+			accessible := meta.FindStatusCondition(resource.Status.Conditions, trainingv1alpha1.ConditionRepositoryAccessible)
+			Expect(accessible).NotTo(BeNil())
+			Expect(accessible.Status).To(Equal(metav1.ConditionFalse))
+			Expect(accessible.Reason).To(Equal("NotFound"))
+			Expect(meta.IsStatusConditionTrue(resource.Status.Conditions, trainingv1alpha1.ConditionCredentialsValid)).To(BeTrue())
+		})
+
+		It("should report an invalid repository on the status", func() {
+			resource := &trainingv1alpha1.GithubIssue{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			resource.Spec.Repository = "https://api.github.com/repos/myrepo/myuser/issues"
+			Expect(k8sClient.Update(ctx, resource)).To(Succeed())
+
+			controllerReconciler := &GithubIssueReconciler{
+				Client:     k8sClient,
+				Scheme:     k8sClient.Scheme(),
+				NewTracker: gitclient.NewMemoryTrackerFactory(),
+			}
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			Expect(resource.Status.Message).To(ContainSubstring("invalid repository"))
+			Expect(meta.IsStatusConditionFalse(resource.Status.Conditions, trainingv1alpha1.ConditionRepositoryAccessible)).To(BeTrue())
+			synced := meta.FindStatusCondition(resource.Status.Conditions, trainingv1alpha1.ConditionSynced)
+			Expect(synced).NotTo(BeNil())
+			Expect(synced.Status).To(Equal(metav1.ConditionFalse))
+			Expect(synced.Reason).To(Equal("InvalidRepository"))
+		})
+
+		It("should report a synced issue in its conditions", func() {
+			controllerReconciler := &GithubIssueReconciler{
+				Client:     k8sClient,
+				Scheme:     k8sClient.Scheme(),
+				NewTracker: gitclient.NewMemoryTrackerFactory(),
+			}
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			resource := &trainingv1alpha1.GithubIssue{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			Expect(resource.Status.ObservedGeneration).To(Equal(resource.Generation))
+			for _, conditionType := range []string{
+				trainingv1alpha1.ConditionReady,
+				trainingv1alpha1.ConditionSynced,
+				trainingv1alpha1.ConditionCredentialsValid,
+				trainingv1alpha1.ConditionRepositoryAccessible,
+			} {
+				condition := meta.FindStatusCondition(resource.Status.Conditions, conditionType)
+				Expect(condition).NotTo(BeNil(), conditionType)
+				Expect(condition.Status).To(Equal(metav1.ConditionTrue), conditionType)
+				Expect(condition.ObservedGeneration).To(Equal(resource.Generation), conditionType)
+			}
+			Expect(meta.IsStatusConditionFalse(resource.Status.Conditions, trainingv1alpha1.ConditionRateLimited)).To(BeTrue())
+		})
+
+		It("should report rejected credentials in its conditions and retry", func() {
+			controllerReconciler := &GithubIssueReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+				NewTracker: func(ref gitclient.RepoRef, opts ...gitclient.Option) (gitclient.IssueTracker, error) {
+					return &unauthorizedTracker{MemoryTracker: gitclient.NewMemoryTracker()}, nil
+				},
+			}
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).To(HaveOccurred())
+
+			resource := &trainingv1alpha1.GithubIssue{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			credentials := meta.FindStatusCondition(resource.Status.Conditions, trainingv1alpha1.ConditionCredentialsValid)
+			Expect(credentials).NotTo(BeNil())
+			Expect(credentials.Status).To(Equal(metav1.ConditionFalse))
+			Expect(credentials.Reason).To(Equal("Unauthorized"))
+			ready := meta.FindStatusCondition(resource.Status.Conditions, trainingv1alpha1.ConditionReady)
+			Expect(ready).NotTo(BeNil())
+			Expect(ready.Status).To(Equal(metav1.ConditionFalse))
+			Expect(ready.Reason).To(Equal("Unauthorized"))
+			Expect(resource.Status.Message).To(ContainSubstring("Bad credentials"))
+		})
+
+		Context("when managing the issue state", func() {
+			var (
+				tracker    *gitclient.MemoryTracker
+				reconciler *GithubIssueReconciler
+			)
+
+			BeforeEach(func() {
+				reconciler, tracker = memoryReconciler()
+				reconciler.ResyncPeriod = time.Minute
+			})
+
+			reconcileIssue := func() gitclient.GitIssue {
+				resource, issue := reconcileAndFetch(reconciler, tracker)
+				Expect(resource.Status.State).To(Equal(issue.Status))
+				return issue
+			}
+
+			It("should check the issue again after the resync period", func() {
+				result, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.RequeueAfter).To(Equal(time.Minute))
+			})
+
+			It("should close and reopen the issue from spec.state", func() {
+				Expect(reconcileIssue().Status).To(Equal("open"))
+
+				updateSpec(func(spec *trainingv1alpha1.GithubIssueSpec) {
+					spec.State = "closed"
+					spec.StateReason = "not_planned"
+				})
+				issue := reconcileIssue()
+				Expect(issue.Status).To(Equal("closed"))
+				Expect(issue.StateReason).To(Equal("not_planned"))
+
+				updateSpec(func(spec *trainingv1alpha1.GithubIssueSpec) { spec.State = "open" })
+				Expect(reconcileIssue().Status).To(Equal("open"))
+			})
+
+			It("should open the issue closed when spec.state says so", func() {
+				updateSpec(func(spec *trainingv1alpha1.GithubIssueSpec) { spec.State = "closed" })
+				issue := reconcileIssue()
+				Expect(issue.Status).To(Equal("closed"))
+				Expect(issue.StateReason).To(Equal("completed"))
+			})
+
+			It("should revert a reopened issue under the Enforce policy", func() {
+				updateSpec(func(spec *trainingv1alpha1.GithubIssueSpec) {
+					spec.State = "closed"
+					spec.StatePolicy = trainingv1alpha1.StatePolicyEnforce
+				})
+				issue := reconcileIssue()
+
+				_, err := tracker.EditIssue(ctx, issue.Id, gitclient.IssueEdit{State: "open"})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(reconcileIssue().Status).To(Equal("closed"))
+			})
+
+			It("should keep a reopened issue open under the Respect policy until the spec changes", func() {
+				updateSpec(func(spec *trainingv1alpha1.GithubIssueSpec) {
+					spec.State = "closed"
+					spec.StatePolicy = trainingv1alpha1.StatePolicyRespect
+				})
+				issue := reconcileIssue()
+
+				_, err := tracker.EditIssue(ctx, issue.Id, gitclient.IssueEdit{State: "open"})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(reconcileIssue().Status).To(Equal("open"))
+
+				updateSpec(func(spec *trainingv1alpha1.GithubIssueSpec) { spec.Title = "retitled" })
+				Expect(reconcileIssue().Status).To(Equal("closed"))
+			})
+
+			It("should report the metadata of the issue on the status", func() {
+				issue := reconcileIssue()
+				_, err := tracker.AddComment(ctx, issue.Id, "looking into it")
+				Expect(err).NotTo(HaveOccurred())
+				updateSpec(func(spec *trainingv1alpha1.GithubIssueSpec) {
+					spec.State = "closed"
+					spec.Labels = []trainingv1alpha1.Label{{Name: "bug"}}
+				})
+				reconcileIssue()
+
+				resource := &trainingv1alpha1.GithubIssue{}
+				Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+				Expect(resource.Status.IssueNumber).To(Equal(issue.Id))
+				Expect(resource.Status.HTMLURL).To(Equal(fmt.Sprintf("https://github.com/myrepo/myuser/issues/%d", issue.Id)))
+				Expect(resource.Status.CreatedAt).NotTo(BeNil())
+				Expect(resource.Status.LastUpdated).NotTo(BeNil())
+				Expect(resource.Status.ClosedAt).NotTo(BeNil())
+				Expect(resource.Status.Comments).To(Equal(1))
+				Expect(resource.Status.Labels).To(Equal([]string{"bug"}))
+
+				updateSpec(func(spec *trainingv1alpha1.GithubIssueSpec) { spec.State = "open" })
+				reconcileIssue()
+				Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+				Expect(resource.Status.ClosedAt).To(BeNil())
+			})
+
+			It("should leave the state alone without spec.state", func() {
+				issue := reconcileIssue()
+				_, err := tracker.CloseIssue(ctx, issue.Id)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(reconcileIssue().Status).To(Equal("closed"))
+			})
+		})
+
+		Context("when managing labels", func() {
+			var (
+				tracker    *gitclient.MemoryTracker
+				reconciler *GithubIssueReconciler
+			)
+
+			BeforeEach(func() {
+				reconciler, tracker = memoryReconciler()
+			})
+
+			setLabels := func(labels ...trainingv1alpha1.Label) {
+				updateSpec(func(spec *trainingv1alpha1.GithubIssueSpec) { spec.Labels = labels })
+			}
+
+			reconcileLabels := func() (gitclient.GitIssue, []string) {
+				_, issue := reconcileAndFetch(reconciler, tracker)
+				var names []string
+				for _, label := range issue.Labels {
+					names = append(names, label.Name)
+				}
+				return issue, names
+			}
+
+			It("should create missing labels with their color and description", func() {
+				_, err := tracker.CreateLabel(ctx, gitclient.GitLabel{Name: "bug", Color: "d73a4a"})
+				Expect(err).NotTo(HaveOccurred())
+				setLabels(
+					trainingv1alpha1.Label{Name: "bug", Color: "00ff00"},
+					trainingv1alpha1.Label{Name: "needs-triage", Color: "FBCA04", Description: "Not looked at yet"},
+				)
+
+				_, names := reconcileLabels()
+				Expect(names).To(Equal([]string{"bug", "needs-triage"}))
+
+				bug, err := tracker.GetLabel(ctx, "bug")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(bug.Color).To(Equal("d73a4a"))
+				triage, err := tracker.GetLabel(ctx, "needs-triage")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(triage).To(Equal(gitclient.GitLabel{Name: "needs-triage", Color: "fbca04", Description: "Not looked at yet"}))
+			})
+
+			It("should only remove labels the operator added", func() {
+				setLabels(trainingv1alpha1.Label{Name: "bug"})
+				issue, _ := reconcileLabels()
+
+				By("labelling the issue on GitHub")
+				humanLabels := []string{"bug", "wontfix", "needs-triage"}
+				_, err := tracker.EditIssue(ctx, issue.Id, gitclient.IssueEdit{Labels: &humanLabels})
+				Expect(err).NotTo(HaveOccurred())
+
+				setLabels(trainingv1alpha1.Label{Name: "bug"}, trainingv1alpha1.Label{Name: "Needs-Triage"})
+				_, names := reconcileLabels()
+				Expect(names).To(Equal([]string{"bug", "wontfix", "needs-triage"}))
+
+				resource := &trainingv1alpha1.GithubIssue{}
+				Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+				Expect(resource.Status.ManagedLabels).To(Equal([]string{"bug"}))
+
+				setLabels()
+				_, names = reconcileLabels()
+				Expect(names).To(Equal([]string{"wontfix", "needs-triage"}))
+			})
+
+			It("should add back a managed label removed on GitHub", func() {
+				setLabels(trainingv1alpha1.Label{Name: "bug"})
+				issue, _ := reconcileLabels()
+
+				noLabels := []string{}
+				_, err := tracker.EditIssue(ctx, issue.Id, gitclient.IssueEdit{Labels: &noLabels})
+				Expect(err).NotTo(HaveOccurred())
+
+				_, names := reconcileLabels()
+				Expect(names).To(Equal([]string{"bug"}))
+			})
+		})
+
+		Context("when managing assignees", func() {
+			var (
+				tracker    *gitclient.MemoryTracker
+				reconciler *GithubIssueReconciler
+				recorder   *record.FakeRecorder
+			)
+
+			BeforeEach(func() {
+				reconciler, tracker = memoryReconciler()
+				tracker.AddAssignable("octocat", "hubot")
+				recorder = record.NewFakeRecorder(10)
+				reconciler.Recorder = recorder
+			})
+
+			setAssignees := func(assignees ...string) {
+				updateSpec(func(spec *trainingv1alpha1.GithubIssueSpec) { spec.Assignees = assignees })
+			}
+
+			reconcileAssignees := func() (*trainingv1alpha1.GithubIssue, []string) {
+				resource, issue := reconcileAndFetch(reconciler, tracker)
+				var logins []string
+				for _, assignee := range issue.Assignees {
+					logins = append(logins, assignee.Login)
+				}
+				return resource, logins
+			}
+
+			It("should assign the issue and report users who cannot be assigned", func() {
+				setAssignees("octocat", "stranger")
+				resource, logins := reconcileAssignees()
+				Expect(logins).To(Equal([]string{"octocat"}))
+				Expect(resource.Status.Message).To(BeEmpty())
+				condition := meta.FindStatusCondition(resource.Status.Conditions, trainingv1alpha1.ConditionAssigneesValid)
+				Expect(condition).NotTo(BeNil())
+				Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+				Expect(condition.Reason).To(Equal("Unassignable"))
+				Expect(condition.Message).To(ContainSubstring("stranger"))
+				Expect(recorder.Events).To(Receive(HavePrefix("Normal Created")))
+				Expect(recorder.Events).To(Receive(Equal(fmt.Sprintf(
+					"Warning Unassignable Cannot assign issues in this repository to stranger: https://github.com/myrepo/myuser/issues/%d", resource.Status.IssueNumber))))
+
+				tracker.AddAssignable("stranger")
+				resource, logins = reconcileAssignees()
+				Expect(logins).To(Equal([]string{"octocat", "stranger"}))
+				Expect(meta.IsStatusConditionTrue(resource.Status.Conditions, trainingv1alpha1.ConditionAssigneesValid)).To(BeTrue())
+			})
+
+			It("should only unassign users the operator assigned", func() {
+				setAssignees("octocat")
+				resource, _ := reconcileAssignees()
+
+				By("assigning the issue on GitHub")
+				assignees := []string{"octocat", "hubot"}
+				_, err := tracker.EditIssue(ctx, resource.Status.IssueNumber, gitclient.IssueEdit{Assignees: &assignees})
+				Expect(err).NotTo(HaveOccurred())
+
+				setAssignees()
+				resource, logins := reconcileAssignees()
+				Expect(logins).To(Equal([]string{"hubot"}))
+				Expect(resource.Status.ManagedAssignees).To(BeEmpty())
+				Expect(meta.FindStatusCondition(resource.Status.Conditions, trainingv1alpha1.ConditionAssigneesValid)).To(BeNil())
+			})
+
+			It("should translate Kubernetes user names through the identity map", func() {
+				identityMap := &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{Name: "github-identities", Namespace: "default"},
+					Data:       map[string]string{"octocat": "alice@example.com\nsystem:serviceaccount:ci:bot"},
+				}
+				Expect(k8sClient.Create(ctx, identityMap)).To(Succeed())
+				DeferCleanup(func() {
+					Expect(k8sClient.Delete(ctx, identityMap)).To(Succeed())
+				})
+				reconciler.IdentityMap = client.ObjectKeyFromObject(identityMap)
+
+				setAssignees("alice@example.com", "hubot")
+				_, logins := reconcileAssignees()
+				Expect(logins).To(Equal([]string{"octocat", "hubot"}))
+			})
+		})
+
+		Context("when adding the issue to a milestone", func() {
+			var (
+				tracker    *countingTracker
+				reconciler *GithubIssueReconciler
+			)
+
+			BeforeEach(func() {
+				tracker = &countingTracker{MemoryTracker: gitclient.NewMemoryTracker()}
+				reconciler = &GithubIssueReconciler{
+					Client: k8sClient,
+					Scheme: k8sClient.Scheme(),
+					NewTracker: func(ref gitclient.RepoRef, opts ...gitclient.Option) (gitclient.IssueTracker, error) {
+						return tracker, nil
+					},
+				}
+			})
+
+			setMilestone := func(milestone *trainingv1alpha1.Milestone) {
+				updateSpec(func(spec *trainingv1alpha1.GithubIssueSpec) { spec.Milestone = milestone })
+			}
+
+			reconcileMilestone := func() *trainingv1alpha1.GithubIssue {
+				resource, _ := reconcileAndFetch(reconciler, tracker)
+				return resource
+			}
+
+			It("should resolve the milestone by title and cache its number", func() {
+				_, err := tracker.CreateMilestone(ctx, gitclient.GitMilestone{Title: "v1.0"})
+				Expect(err).NotTo(HaveOccurred())
+				v2, err := tracker.CreateMilestone(ctx, gitclient.GitMilestone{Title: "v2.0"})
+				Expect(err).NotTo(HaveOccurred())
+				setMilestone(&trainingv1alpha1.Milestone{Title: "v2.0"})
+
+				resource, issue := reconcileAndFetch(reconciler, tracker)
+				Expect(resource.Status.Milestone).To(Equal(&trainingv1alpha1.MilestoneStatus{Number: v2.Number, Title: "v2.0"}))
+				Expect(issue.Milestone.Number).To(Equal(v2.Number))
+
+				_, err = tracker.EditIssue(ctx, issue.Id, gitclient.IssueEdit{Milestone: 1})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(reconcileMilestone().Status.Milestone.Title).To(Equal("v2.0"))
+				Expect(tracker.listMilestones).To(Equal(1))
+			})
+
+			It("should create a missing milestone with its due date", func() {
+				dueOn := metav1.NewTime(time.Date(2030, time.March, 1, 0, 0, 0, 0, time.UTC))
+				setMilestone(&trainingv1alpha1.Milestone{Title: "v3.0", Create: true, DueOn: &dueOn})
+
+				resource := reconcileMilestone()
+				Expect(resource.Status.Milestone).NotTo(BeNil())
+				Expect(resource.Status.Milestone.Title).To(Equal("v3.0"))
+				milestones, err := tracker.ListMilestones(ctx)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(milestones).To(HaveLen(1))
+				Expect(milestones[0].DueOn).NotTo(BeNil())
+				Expect(milestones[0].DueOn.Equal(dueOn.Time)).To(BeTrue())
+			})
+
+			It("should report a missing milestone on the status until it is created", func() {
+				setMilestone(&trainingv1alpha1.Milestone{Title: "v4.0"})
+				reconciler.ResyncPeriod = time.Hour
+
+				result, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.RequeueAfter).To(Equal(time.Hour))
+				resource := &trainingv1alpha1.GithubIssue{}
+				Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+				Expect(resource.Status.Message).To(ContainSubstring(`milestone "v4.0" not found`))
+				Expect(resource.Status.Milestone).To(BeNil())
+
+				By("retrying once the milestone exists")
+				v4, err := tracker.CreateMilestone(ctx, gitclient.GitMilestone{Title: "v4.0"})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(reconcileMilestone().Status.Milestone).To(Equal(&trainingv1alpha1.MilestoneStatus{Number: v4.Number, Title: "v4.0"}))
+			})
+		})
+
+		Context("when emitting events", func() {
+			var (
+				recorder   *record.FakeRecorder
+				newTracker gitclient.TrackerFactory
+			)
+
+			BeforeEach(func() {
+				recorder = record.NewFakeRecorder(10)
+				newTracker = gitclient.NewMemoryTrackerFactory()
+			})
+
+			reconcileWith := func(newTracker gitclient.TrackerFactory) error {
+				reconciler := &GithubIssueReconciler{
+					Client:     k8sClient,
+					Scheme:     k8sClient.Scheme(),
+					NewTracker: newTracker,
+					Recorder:   recorder,
+				}
+				_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+				return err
+			}
+
+			It("should report creating, updating and closing the issue with its URL", func() {
+				Expect(reconcileWith(newTracker)).To(Succeed())
+				Expect(recorder.Events).To(Receive(Equal("Normal Created Created issue #1: https://github.com/myrepo/myuser/issues/1")))
+
+				resource := &trainingv1alpha1.GithubIssue{}
+				Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+				resource.Spec.Title = "retitled"
+				resource.Spec.State = "closed"
+				Expect(k8sClient.Update(ctx, resource)).To(Succeed())
+				Expect(reconcileWith(newTracker)).To(Succeed())
+				Expect(recorder.Events).To(Receive(Equal("Normal Closed Closed issue #1 as completed: https://github.com/myrepo/myuser/issues/1")))
+				Expect(recorder.Events).To(Receive(Equal("Normal Updated Updated title, description of issue #1: https://github.com/myrepo/myuser/issues/1")))
+
+				Expect(reconcileWith(newTracker)).To(Succeed())
+				Expect(recorder.Events).NotTo(Receive())
+			})
+
+			It("should count the issues it creates, updates and closes", func() {
+				created := testutil.ToFloat64(issuesCreated.WithLabelValues("github.com"))
+				updated := testutil.ToFloat64(issuesUpdated.WithLabelValues("github.com"))
+				closed := testutil.ToFloat64(issuesClosed.WithLabelValues("github.com"))
+
+				Expect(reconcileWith(newTracker)).To(Succeed())
+				Expect(testutil.ToFloat64(issuesCreated.WithLabelValues("github.com"))).To(Equal(created + 1))
+
+				resource := &trainingv1alpha1.GithubIssue{}
+				Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+				resource.Spec.Title = "retitled"
+				resource.Spec.State = "closed"
+				Expect(k8sClient.Update(ctx, resource)).To(Succeed())
+				Expect(reconcileWith(newTracker)).To(Succeed())
+				Expect(testutil.ToFloat64(issuesUpdated.WithLabelValues("github.com"))).To(Equal(updated + 1))
+				Expect(testutil.ToFloat64(issuesClosed.WithLabelValues("github.com"))).To(Equal(closed + 1))
+
+				Expect(reconcileWith(newTracker)).To(Succeed())
+				Expect(testutil.ToFloat64(issuesUpdated.WithLabelValues("github.com"))).To(Equal(updated + 1))
+			})
+
+			It("should report adopting an existing issue", func() {
+				tracker, err := newTracker(testRepo)
+				Expect(err).NotTo(HaveOccurred())
+				existing, err := tracker.AddIssue(ctx, "filed by hand", "")
+				Expect(err).NotTo(HaveOccurred())
+
+				resource := &trainingv1alpha1.GithubIssue{}
+				Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+				resource.Spec.IssueNumber = existing.Id
+				Expect(k8sClient.Update(ctx, resource)).To(Succeed())
+
+				Expect(reconcileWith(newTracker)).To(Succeed())
+				Expect(recorder.Events).To(Receive(HavePrefix("Normal Adopted Adopted issue #1: https://github.com/myrepo/myuser/issues/1")))
+			})
+
+			It("should warn about rate limits and rejected requests", func() {
+				Expect(reconcileWith(func(ref gitclient.RepoRef, opts ...gitclient.Option) (gitclient.IssueTracker, error) {
+					return &rateLimitedTracker{MemoryTracker: gitclient.NewMemoryTracker(), retryAt: time.Now().Add(time.Minute)}, nil
+				})).To(Succeed())
+				Expect(recorder.Events).To(Receive(SatisfyAll(HavePrefix("Warning RateLimited"), HaveSuffix("https://github.com/myrepo/myuser"))))
+
+				Expect(reconcileWith(func(ref gitclient.RepoRef, opts ...gitclient.Option) (gitclient.IssueTracker, error) {
+					return &rejectingTracker{MemoryTracker: gitclient.NewMemoryTracker()}, nil
+				})).To(Succeed())
+				Expect(recorder.Events).To(Receive(SatisfyAll(HavePrefix("Warning ValidationFailed"), ContainSubstring("missing_field"))))
+			})
+		})
+
+		Context("when the resource is deleted", func() {
+			var (
+				tracker    *gitclient.MemoryTracker
+				reconciler *GithubIssueReconciler
+				recorder   *record.FakeRecorder
+			)
+
+			BeforeEach(func() {
+				tracker = gitclient.NewMemoryTracker()
+				recorder = record.NewFakeRecorder(10)
+				reconciler = &GithubIssueReconciler{
+					Client: k8sClient,
+					Scheme: k8sClient.Scheme(),
+					NewTracker: func(ref gitclient.RepoRef, opts ...gitclient.Option) (gitclient.IssueTracker, error) {
+						return tracker, nil
+					},
+					Recorder: recorder,
+				}
+			})
+
+			// fileAndDelete files the issue and deletes the resource, which
+			// the finalizer keeps around.
+			fileAndDelete := func(policy trainingv1alpha1.DeletionPolicy) int {
+				resource := &trainingv1alpha1.GithubIssue{}
+				Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+				resource.Spec.DeletionPolicy = policy
+				Expect(k8sClient.Update(ctx, resource)).To(Succeed())
+
+				_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+				Expect(resource.Finalizers).To(ContainElement(issueFinalizer))
+
+				Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+				Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+				Expect(resource.DeletionTimestamp).NotTo(BeNil())
+				return resource.Status.IssueNumber
+			}
+
+			expectDeleted := func() {
+				_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+				Expect(err).NotTo(HaveOccurred())
+				err = k8sClient.Get(ctx, typeNamespacedName, &trainingv1alpha1.GithubIssue{})
+				Expect(errors.IsNotFound(err)).To(BeTrue())
+
+				By("recreating the resource for the cleanup")
+				resource := &trainingv1alpha1.GithubIssue{
+					ObjectMeta: metav1.ObjectMeta{Name: resourceName, Namespace: "default"},
+					Spec:       trainingv1alpha1.GithubIssueSpec{Repository: "git@github.com:myrepo/myuser.git"},
+				}
+				Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+			}
+
+			It("should close the issue with a comment under the Close policy", func() {
+				number := fileAndDelete(trainingv1alpha1.DeletionPolicyClose)
+				expectDeleted()
+
+				issue, err := tracker.GetIssue(ctx, number)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(issue.Status).To(Equal("closed"))
+				Expect(tracker.CommentsOf(number)).To(ConsistOf(HaveField("Body", ContainSubstring("default/test-resource was deleted"))))
+				Expect(recorder.Events).To(Receive(ContainSubstring("Normal Created")))
+				Expect(recorder.Events).To(Receive(ContainSubstring("Normal Closed")))
+			})
+
+			It("should lock the issue under the Lock policy", func() {
+				number := fileAndDelete(trainingv1alpha1.DeletionPolicyLock)
+				expectDeleted()
+
+				issue, err := tracker.GetIssue(ctx, number)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(issue.Locked).To(BeTrue())
+				Expect(issue.Status).To(Equal("open"))
+			})
+
+			It("should leave the issue alone under the Orphan policy", func() {
+				number := fileAndDelete(trainingv1alpha1.DeletionPolicyOrphan)
+				expectDeleted()
+
+				issue, err := tracker.GetIssue(ctx, number)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(issue.Status).To(Equal("open"))
+				Expect(issue.Locked).To(BeFalse())
+			})
+
+			It("should release the resource under the Orphan policy when its credentials secret is gone", func() {
+				number := fileAndDelete(trainingv1alpha1.DeletionPolicyOrphan)
+
+				resource := &trainingv1alpha1.GithubIssue{}
+				Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+				resource.Spec.CredentialsRef = &trainingv1alpha1.CredentialsReference{Name: "deleted-credentials"}
+				Expect(k8sClient.Update(ctx, resource)).To(Succeed())
+				expectDeleted()
+
+				issue, err := tracker.GetIssue(ctx, number)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(issue.Status).To(Equal("open"))
+			})
+
+			It("should release a resource that never filed an issue without its credentials", func() {
+				resource := &trainingv1alpha1.GithubIssue{}
+				Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+				resource.Spec.CredentialsRef = &trainingv1alpha1.CredentialsReference{Name: "deleted-credentials"}
+				Expect(k8sClient.Update(ctx, resource)).To(Succeed())
+
+				_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+				Expect(resource.Finalizers).To(ContainElement(issueFinalizer))
+				Expect(resource.Status.IssueNumber).To(BeZero())
+
+				Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+				expectDeleted()
+			})
+
+			It("should block the deletion until the issue can be closed", func() {
+				fileAndDelete(trainingv1alpha1.DeletionPolicyClose)
+
+				reconciler.NewTracker = func(ref gitclient.RepoRef, opts ...gitclient.Option) (gitclient.IssueTracker, error) {
+					return &failingTracker{MemoryTracker: tracker}, nil
+				}
+				_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+				Expect(err).To(HaveOccurred())
+
+				resource := &trainingv1alpha1.GithubIssue{}
+				Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+				condition := meta.FindStatusCondition(resource.Status.Conditions, trainingv1alpha1.ConditionDeletionBlocked)
+				Expect(condition).NotTo(BeNil())
+				Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+				Expect(condition.Reason).To(Equal("CommentFailed"))
+				Expect(condition.Message).To(ContainSubstring("502"))
+				Expect(recorder.Events).To(Receive(HavePrefix("Normal Created")))
+				Expect(recorder.Events).To(Receive(HavePrefix("Normal Closed")))
+				Expect(recorder.Events).To(Receive(SatisfyAll(
+					HavePrefix("Warning DeletionBlocked"),
+					HaveSuffix(fmt.Sprintf("https://github.com/myrepo/myuser/issues/%d", resource.Status.IssueNumber)))))
+
+				By("retrying once GitHub recovers")
+				reconciler.NewTracker = func(ref gitclient.RepoRef, opts ...gitclient.Option) (gitclient.IssueTracker, error) {
+					return tracker, nil
+				}
+				expectDeleted()
+				Expect(tracker.CommentsOf(resource.Status.IssueNumber)).To(HaveLen(1))
+			})
+
+			It("should comment only once when closing the issue has to be retried", func() {
+				number := fileAndDelete(trainingv1alpha1.DeletionPolicyClose)
+
+				flaky := &closeFailingTracker{MemoryTracker: tracker, failures: 1}
+				reconciler.NewTracker = func(ref gitclient.RepoRef, opts ...gitclient.Option) (gitclient.IssueTracker, error) {
+					return flaky, nil
+				}
+				_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+				Expect(err).To(HaveOccurred())
+
+				resource := &trainingv1alpha1.GithubIssue{}
+				Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+				Expect(meta.FindStatusCondition(resource.Status.Conditions, trainingv1alpha1.ConditionDeletionBlocked)).To(HaveField("Reason", "CloseFailed"))
+
+				expectDeleted()
+				issue, err := tracker.GetIssue(ctx, number)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(issue.Status).To(Equal("closed"))
+				Expect(tracker.CommentsOf(number)).To(HaveLen(1))
+			})
+		})
+
+		It("should not create issues in read-only mode", func() {
+			newTracker := gitclient.NewMemoryTrackerFactory()
+			recorder := record.NewFakeRecorder(10)
+			controllerReconciler := &GithubIssueReconciler{
+				Client:     k8sClient,
+				Scheme:     k8sClient.Scheme(),
+				NewTracker: newTracker,
+				Recorder:   recorder,
+				ReadOnly:   true,
+			}
+			result, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(Equal(DefaultCredentialsCheckInterval))
+
+			tracker, err := newTracker(testRepo)
+			Expect(err).NotTo(HaveOccurred())
+			gitissues, err := tracker.GetIssues(ctx, gitclient.ListOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(gitissues).To(BeEmpty())
+			Expect(recorder.Events).To(Receive(And(ContainSubstring("ReadOnly"), HaveSuffix("https://github.com/myrepo/myuser"))))
+
+			resource := &trainingv1alpha1.GithubIssue{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			Expect(resource.Status.Message).To(ContainSubstring("read-only mode"))
+		})
+
+		It("should report the bound issue without editing it when the credentials cannot write", func() {
+			newTracker := gitclient.NewMemoryTrackerFactory()
+			tracker, err := newTracker(testRepo)
+			Expect(err).NotTo(HaveOccurred())
+			existing, err := tracker.AddIssue(ctx, "existing issue", "filed by hand")
+			Expect(err).NotTo(HaveOccurred())
+
+			resource := &trainingv1alpha1.GithubIssue{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			resource.Spec.IssueNumber = existing.Id
+			Expect(k8sClient.Update(ctx, resource)).To(Succeed())
+
+			readOnly := true
+			monitor := &CredentialsMonitor{Interval: time.Minute, Checks: []CredentialCheck{{
+				Host: "github.com",
+				Check: func(context.Context) (gitclient.CredentialInfo, error) {
+					return gitclient.CredentialInfo{Login: "reader", ReadOnly: readOnly, ExpiresAt: time.Now().Add(time.Hour)}, nil
+				},
+			}}}
+			monitor.CheckNow(ctx)
+			recorder := record.NewFakeRecorder(10)
+			controllerReconciler := &GithubIssueReconciler{
+				Client:      k8sClient,
+				Scheme:      k8sClient.Scheme(),
+				NewTracker:  newTracker,
+				Recorder:    recorder,
+				Credentials: monitor,
+			}
+			result, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(Equal(time.Minute))
+			Expect(recorder.Events).To(Receive(ContainSubstring("CredentialsExpiring")))
+			Expect(recorder.Events).To(Receive(And(
+				ContainSubstring("ReadOnly"),
+				HaveSuffix(fmt.Sprintf("https://github.com/myrepo/myuser/issues/%d", existing.Id)),
+			)))
+
+			unchanged, err := tracker.GetIssue(ctx, existing.Id)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(unchanged.Title).To(Equal("existing issue"))
+
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			Expect(resource.Status.IssueNumber).To(Equal(existing.Id))
+			Expect(resource.Status.State).To(Equal("open"))
+			Expect(resource.Status.Message).To(ContainSubstring("lack write access"))
+
+			By("syncing the issue once the credentials can write again")
+			readOnly = false
+			monitor.CheckNow(ctx)
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			synced, err := tracker.GetIssue(ctx, existing.Id)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(synced.Title).To(Equal(resource.Spec.Title))
+		})
+
+		Context("with per-resource credentials", func() {
+			var (
+				server *httptest.Server
+				auth   chan string
+			)
+
+			BeforeEach(func() {
+				auth = make(chan string, 10)
+				server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					auth <- r.Header.Get("Authorization")
+					if r.Method == http.MethodPost {
+						fmt.Fprint(w, `{"number": 1, "title": "test issue", "state": "open"}`)
+						return
+					}
+					fmt.Fprint(w, `[]`)
+				}))
+			})
+
+			AfterEach(func() {
+				server.Close()
+				secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "github-credentials", Namespace: "default"}}
+				Expect(client.IgnoreNotFound(k8sClient.Delete(ctx, secret))).To(Succeed())
+			})
+
+			createSecret := func(data map[string][]byte) {
+				secret := &corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Name: "github-credentials", Namespace: "default"},
+					Data:       data,
+				}
+				Expect(k8sClient.Create(ctx, secret)).To(Succeed())
+			}
+
+			It("should authenticate with the token from spec.credentialsRef", func() {
+				createSecret(map[string][]byte{"pat": []byte("from-secret")})
+				resource := &trainingv1alpha1.GithubIssue{}
+				Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+				resource.Spec.CredentialsRef = &trainingv1alpha1.CredentialsReference{Name: "github-credentials", Key: "pat"}
+				Expect(k8sClient.Update(ctx, resource)).To(Succeed())
+
+				controllerReconciler := &GithubIssueReconciler{
+					Client:     k8sClient,
+					Scheme:     k8sClient.Scheme(),
+					NewTracker: gitclient.NewGitHubTrackerFactory(gitclient.WithBaseURL(server.URL), gitclient.WithToken("operator")),
+				}
+				_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(auth).To(Receive(Equal("Bearer from-secret")))
+
+				Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+				Expect(resource.Status.IssueNumber).To(Equal(1))
+			})
+
+			It("should fall back to the namespace's default credentials secret", func() {
+				createSecret(map[string][]byte{"token": []byte("namespace-default")})
+
+				controllerReconciler := &GithubIssueReconciler{
+					Client:                   k8sClient,
+					Scheme:                   k8sClient.Scheme(),
+					NewTracker:               gitclient.NewGitHubTrackerFactory(gitclient.WithBaseURL(server.URL), gitclient.WithToken("operator")),
+					DefaultCredentialsSecret: "github-credentials",
+				}
+				_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(auth).To(Receive(Equal("Bearer namespace-default")))
+			})
+
+			It("should use the operator's credentials without a default secret in the namespace if allowed", func() {
+				controllerReconciler := &GithubIssueReconciler{
+					Client:                        k8sClient,
+					Scheme:                        k8sClient.Scheme(),
+					NewTracker:                    gitclient.NewGitHubTrackerFactory(gitclient.WithBaseURL(server.URL), gitclient.WithToken("operator")),
+					DefaultCredentialsSecret:      "github-credentials",
+					FallbackToOperatorCredentials: true,
+				}
+				_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(auth).To(Receive(Equal("Bearer operator")))
+			})
+
+			It("should not use the operator's credentials without a default secret in the namespace", func() {
+				controllerReconciler := &GithubIssueReconciler{
+					Client:                   k8sClient,
+					Scheme:                   k8sClient.Scheme(),
+					NewTracker:               gitclient.NewGitHubTrackerFactory(gitclient.WithBaseURL(server.URL), gitclient.WithToken("operator")),
+					DefaultCredentialsSecret: "github-credentials",
+				}
+				_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(auth).NotTo(Receive())
+
+				resource := &trainingv1alpha1.GithubIssue{}
+				Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+				Expect(resource.Status.IssueNumber).To(BeZero())
+				Expect(resource.Status.Message).To(ContainSubstring("default credentials secret github-credentials not found"))
+			})
+
+			It("should report a missing credentials secret on the status", func() {
+				resource := &trainingv1alpha1.GithubIssue{}
+				Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+				resource.Spec.CredentialsRef = &trainingv1alpha1.CredentialsReference{Name: "github-credentials"}
+				Expect(k8sClient.Update(ctx, resource)).To(Succeed())
+
+				controllerReconciler := &GithubIssueReconciler{
+					Client:     k8sClient,
+					Scheme:     k8sClient.Scheme(),
+					NewTracker: gitclient.NewGitHubTrackerFactory(gitclient.WithBaseURL(server.URL), gitclient.WithToken("operator")),
+				}
+				_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(auth).NotTo(Receive())
+
+				Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+				Expect(resource.Status.Message).To(Equal("credentials secret github-credentials not found"))
+			})
 		})
 	})
 })
+
+// rateLimitedTracker fails every call to the GitHub API with a rate limit
+// error.
+type rateLimitedTracker struct {
+	*gitclient.MemoryTracker
+	retryAt time.Time
+}
+
+func (t *rateLimitedTracker) IterateIssues(ctx context.Context, opts gitclient.ListOptions, fn func(gitclient.GitIssue) bool) error {
+	return &gitclient.RateLimitError{RetryAt: t.retryAt}
+}
+
+func (t *rateLimitedTracker) AddIssue(ctx context.Context, title string, desc string) (gitclient.GitIssue, error) {
+	return gitclient.GitIssue{}, &gitclient.RateLimitError{RetryAt: t.retryAt}
+}
+
+// unauthorizedTracker fails every search for issues as if the token had
+// been revoked.
+type unauthorizedTracker struct {
+	*gitclient.MemoryTracker
+}
+
+func (t *unauthorizedTracker) IterateIssues(ctx context.Context, opts gitclient.ListOptions, fn func(gitclient.GitIssue) bool) error {
+	return &gitclient.APIError{StatusCode: 401, Message: "Bad credentials"}
+}
+
+// rejectingTracker fails to create issues with a validation error.
+type rejectingTracker struct {
+	*gitclient.MemoryTracker
+}
+
+func (t *rejectingTracker) AddIssue(ctx context.Context, title string, desc string) (gitclient.GitIssue, error) {
+	return gitclient.GitIssue{}, &gitclient.APIError{
+		StatusCode: 422,
+		Message:    "Validation Failed",
+		Errors:     []gitclient.FieldError{{Resource: "Issue", Field: "title", Code: "missing_field"}},
+	}
+}
+
+// failingTracker fails every comment with a server error.
+type failingTracker struct {
+	*gitclient.MemoryTracker
+}
+
+func (t *failingTracker) AddComment(ctx context.Context, Id int, body string) (gitclient.GitComment, error) {
+	return gitclient.GitComment{}, &gitclient.APIError{StatusCode: 502, Message: "Bad Gateway"}
+}
+
+// closeFailingTracker fails the given number of edits with a server error
+// before letting them through.
+type closeFailingTracker struct {
+	*gitclient.MemoryTracker
+	failures int
+}
+
+func (t *closeFailingTracker) EditIssue(ctx context.Context, Id int, edit gitclient.IssueEdit) (gitclient.GitIssue, error) {
+	if t.failures > 0 {
+		t.failures--
+		return gitclient.GitIssue{}, &gitclient.APIError{StatusCode: 502, Message: "Bad Gateway"}
+	}
+	return t.MemoryTracker.EditIssue(ctx, Id, edit)
+}
+
+// countingTracker counts how often the milestones of the repository are
+// listed.
+type countingTracker struct {
+	*gitclient.MemoryTracker
+	listMilestones int
+}
+
+func (t *countingTracker) ListMilestones(ctx context.Context) ([]gitclient.GitMilestone, error) {
+	t.listMilestones++
+	return t.MemoryTracker.ListMilestones(ctx)
+}
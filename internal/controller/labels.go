@@ -0,0 +1,148 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"strings"
+
+	trainingv1alpha1 "github.com/zszabo-rh/issues-operator/api/v1alpha1"
+	"github.com/zszabo-rh/issues-operator/gitclient"
+)
+
+// nameSet holds label names or logins, which GitHub compares
+// case-insensitively, and so does nameSet.
+type nameSet map[string]bool
+
+func newNameSet(names ...string) nameSet {
+	set := nameSet{}
+	for _, name := range names {
+		set[strings.ToLower(name)] = true
+	}
+	return set
+}
+
+func (s nameSet) Has(name string) bool {
+	return s[strings.ToLower(name)]
+}
+
+// syncNames returns current with the names in spec added and the names in
+// managed that were dropped from spec removed, or nil if that changes nothing.
+// Names someone else added are kept.
+func syncNames(current, spec, managed []string) []string {
+	specSet := newNameSet(spec...)
+	managedSet := newNameSet(managed...)
+	currentSet := newNameSet(current...)
+
+	changed := false
+	names := []string{}
+	for _, name := range current {
+		if managedSet.Has(name) && !specSet.Has(name) {
+			changed = true
+			continue
+		}
+		names = append(names, name)
+	}
+	for _, name := range spec {
+		if !currentSet.Has(name) {
+			changed = true
+			names = append(names, name)
+		}
+	}
+
+	if !changed {
+		return nil
+	}
+	return names
+}
+
+// managedNames returns the names the operator manages once current has been
+// synced with syncNames: those it managed before and those it is about to
+// add. Names someone else added first are not managed, so they stay when they
+// are dropped from spec.
+func managedNames(current, spec, managed []string) []string {
+	managedSet := newNameSet(managed...)
+	currentSet := newNameSet(current...)
+
+	var names []string
+	for _, name := range spec {
+		if managedSet.Has(name) || !currentSet.Has(name) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// desiredLabels returns the labels existing should carry, or nil if its labels
+// are up to date. Labels in spec.labels are added. Labels the operator added
+// earlier and that were dropped from spec.labels are removed. Other labels,
+// e.g. those applied on GitHub, are kept.
+func desiredLabels(res *trainingv1alpha1.GithubIssue, existing gitclient.GitIssue) []string {
+	return syncNames(labelNames(existing.Labels), specLabelNames(res), res.Status.ManagedLabels)
+}
+
+// managedLabels returns the labels the operator manages once existing carries
+// the labels from desiredLabels.
+func managedLabels(res *trainingv1alpha1.GithubIssue, existing gitclient.GitIssue) []string {
+	return managedNames(labelNames(existing.Labels), specLabelNames(res), res.Status.ManagedLabels)
+}
+
+func labelNames(labels []gitclient.GitLabel) []string {
+	names := make([]string, 0, len(labels))
+	for _, label := range labels {
+		names = append(names, label.Name)
+	}
+	return names
+}
+
+func specLabelNames(res *trainingv1alpha1.GithubIssue) []string {
+	names := make([]string, 0, len(res.Spec.Labels))
+	for _, label := range res.Spec.Labels {
+		names = append(names, label.Name)
+	}
+	return names
+}
+
+// ensureLabels creates the labels from spec.labels that existing does not
+// carry yet and that are missing from the repository. Applying a missing
+// label to an issue would create it as well, but without its color and
+// description.
+func ensureLabels(ctx context.Context, client gitclient.IssueTracker, res *trainingv1alpha1.GithubIssue, existing gitclient.GitIssue) error {
+	current := newNameSet(labelNames(existing.Labels)...)
+	for _, label := range res.Spec.Labels {
+		if current.Has(label.Name) {
+			continue
+		}
+		_, err := client.GetLabel(ctx, label.Name)
+		if err == nil {
+			continue
+		}
+		if !gitclient.IsNotFound(err) {
+			return err
+		}
+		_, err = client.CreateLabel(ctx, gitclient.GitLabel{
+			Name:        label.Name,
+			Color:       strings.ToLower(label.Color),
+			Description: label.Description,
+		})
+		// Someone else may have created the label in the meantime.
+		if err != nil && !gitclient.IsAlreadyExists(err) {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	trainingv1alpha1 "github.com/zszabo-rh/issues-operator/api/v1alpha1"
+	"github.com/zszabo-rh/issues-operator/gitclient"
+)
+
+var (
+	credentialValid = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "issues_operator_github_credential_valid",
+		Help: "Whether GitHub accepted the operator's credential in the last check.",
+	}, []string{"host", "credential"})
+
+	credentialReadOnly = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "issues_operator_github_credential_read_only",
+		Help: "Whether the operator's credential lacks write access to issues.",
+	}, []string{"host", "credential"})
+
+	credentialExpiry = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "issues_operator_github_credential_expiry_timestamp_seconds",
+		Help: "Unix time the operator's credential expires at. Credentials without expiry are not reported.",
+	}, []string{"host", "credential"})
+)
+
+// Changes the operator made to issues on GitHub, by the host of the
+// repository.
+var (
+	issuesCreated = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "issues_operator_issues_created_total",
+		Help: "Issues the operator opened on GitHub.",
+	}, []string{"host"})
+
+	issuesUpdated = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "issues_operator_issues_updated_total",
+		Help: "Edits the operator made to existing issues on GitHub, including reopening them.",
+	}, []string{"host"})
+
+	issuesClosed = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "issues_operator_issues_closed_total",
+		Help: "Issues the operator closed on GitHub, including on deletion of their GithubIssue.",
+	}, []string{"host"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(credentialValid, credentialReadOnly, credentialExpiry)
+	metrics.Registry.MustRegister(issuesCreated, issuesUpdated, issuesClosed)
+}
+
+// countIssueChange increments counter for the repository of res.
+func countIssueChange(counter *prometheus.CounterVec, res *trainingv1alpha1.GithubIssue) {
+	host := "unknown"
+	if ref, err := gitclient.ParseRepo(res.Spec.Repository); err == nil {
+		host = ref.Host
+	}
+	counter.WithLabelValues(host).Inc()
+}
+
+// recordCredentialMetrics exports the result of a credential check. The
+// metrics are labelled by the name of the credential, so that a rotated
+// token replaces the values of the one before it.
+func recordCredentialMetrics(result credentialResult) {
+	credential := result.info.Credential
+	if credential == "" {
+		credential = "unknown"
+	}
+
+	credentialValid.WithLabelValues(result.host, credential).Set(boolValue(result.err == nil))
+	if result.err != nil {
+		return
+	}
+	credentialReadOnly.WithLabelValues(result.host, credential).Set(boolValue(result.info.ReadOnly))
+	if result.info.ExpiresAt.IsZero() {
+		credentialExpiry.DeleteLabelValues(result.host, credential)
+	} else {
+		credentialExpiry.WithLabelValues(result.host, credential).Set(float64(result.info.ExpiresAt.Unix()))
+	}
+}
+
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,133 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	trainingv1alpha1 "github.com/zszabo-rh/issues-operator/api/v1alpha1"
+	"github.com/zszabo-rh/issues-operator/gitclient"
+)
+
+// milestoneCache remembers the milestones of each repository by title, so
+// that resolving spec.milestone does not list them on every reconcile. The
+// zero value is ready to use.
+type milestoneCache struct {
+	mu    sync.Mutex
+	repos map[string]map[string]gitclient.GitMilestone
+}
+
+func (c *milestoneCache) get(repo, title string) (gitclient.GitMilestone, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	milestone, ok := c.repos[repo][title]
+	return milestone, ok
+}
+
+// set replaces the cached milestones of repo.
+func (c *milestoneCache) set(repo string, milestones []gitclient.GitMilestone) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.repos == nil {
+		c.repos = map[string]map[string]gitclient.GitMilestone{}
+	}
+	byTitle := map[string]gitclient.GitMilestone{}
+	for _, milestone := range milestones {
+		byTitle[milestone.Title] = milestone
+	}
+	c.repos[repo] = byTitle
+}
+
+func (c *milestoneCache) add(repo string, milestone gitclient.GitMilestone) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.repos[repo] == nil {
+		// Without the rest of the list, the next lookup lists again.
+		return
+	}
+	c.repos[repo][milestone.Title] = milestone
+}
+
+// forget drops the cached milestones of repo, e.g. after one was deleted.
+func (c *milestoneCache) forget(repo string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.repos, repo)
+}
+
+// milestoneError reports a spec.milestone that cannot be resolved until the
+// resource or the repository changes.
+type milestoneError struct {
+	message string
+}
+
+func (e *milestoneError) Error() string {
+	return e.message
+}
+
+// resolveMilestone returns the milestone spec.milestone names, creating it if
+// asked to, or nil if spec.milestone is unset.
+func (r *GithubIssueReconciler) resolveMilestone(ctx context.Context, client gitclient.IssueTracker, ref gitclient.RepoRef, res *trainingv1alpha1.GithubIssue) (*gitclient.GitMilestone, error) {
+	spec := res.Spec.Milestone
+	if spec == nil {
+		return nil, nil
+	}
+
+	repo := ref.String()
+	if milestone, ok := r.milestones.get(repo, spec.Title); ok {
+		return &milestone, nil
+	}
+	milestones, err := client.ListMilestones(ctx)
+	if err != nil {
+		return nil, err
+	}
+	r.milestones.set(repo, milestones)
+	if milestone, ok := r.milestones.get(repo, spec.Title); ok {
+		return &milestone, nil
+	}
+
+	if !spec.Create {
+		return nil, &milestoneError{fmt.Sprintf("milestone %q not found in %s", spec.Title, ref.FullName())}
+	}
+	milestone := gitclient.GitMilestone{Title: spec.Title}
+	if spec.DueOn != nil {
+		milestone.DueOn = &spec.DueOn.Time
+	}
+	created, err := client.CreateMilestone(ctx, milestone)
+	if gitclient.IsAlreadyExists(err) {
+		// Someone else created it since it was listed; the next attempt
+		// lists the milestones again.
+		r.milestones.forget(repo)
+		return nil, fmt.Errorf("milestone %q was created concurrently", spec.Title)
+	}
+	if err != nil {
+		return nil, err
+	}
+	log.FromContext(ctx).Info("Created milestone", "title", created.Title, "number", created.Number)
+	r.event(res, corev1.EventTypeNormal, "MilestoneCreated", fmt.Sprintf("Created milestone %q", created.Title))
+	r.milestones.add(repo, created)
+	return &created, nil
+}
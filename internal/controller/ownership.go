@@ -0,0 +1,144 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+
+	trainingv1alpha1 "github.com/zszabo-rh/issues-operator/api/v1alpha1"
+)
+
+// ownerMarkerPattern matches the hidden HTML comment the operator appends to
+// the body of every issue it manages. GitHub does not render HTML comments.
+var ownerMarkerPattern = regexp.MustCompile(`\n*<!-- issues-operator:([^>]*)-->`)
+
+// ownerMarker identifies the GithubIssue that owns a remote issue.
+type ownerMarker struct {
+	UID       string
+	Namespace string
+	Name      string
+	ClusterID string
+}
+
+func ownerMarkerFor(res *trainingv1alpha1.GithubIssue, clusterID string) ownerMarker {
+	return ownerMarker{
+		UID:       string(res.UID),
+		Namespace: res.Namespace,
+		Name:      res.Name,
+		ClusterID: clusterID,
+	}
+}
+
+// String renders the marker as an HTML comment.
+func (m ownerMarker) String() string {
+	fields := []string{
+		"uid=" + url.QueryEscape(m.UID),
+		"resource=" + url.QueryEscape(m.Namespace+"/"+m.Name),
+	}
+	if m.ClusterID != "" {
+		fields = append(fields, "cluster="+url.QueryEscape(m.ClusterID))
+	}
+	return "<!-- issues-operator: " + strings.Join(fields, " ") + " -->"
+}
+
+// Owns reports whether the marker other belongs to the same GithubIssue.
+func (m ownerMarker) Owns(other ownerMarker) bool {
+	return m.UID == other.UID && m.ClusterID == other.ClusterID
+}
+
+// ownerGone reports whether the GithubIssue named by marker no longer exists
+// in this cluster, e.g. because it was deleted under the Orphan policy, which
+// leaves its marker on the issue. Such issues may be adopted by another
+// resource. Owners in other clusters cannot be looked up and are never gone.
+func (r *GithubIssueReconciler) ownerGone(ctx context.Context, marker ownerMarker) (bool, error) {
+	if marker.ClusterID != r.ClusterID || marker.Name == "" {
+		return false, nil
+	}
+	owner := &trainingv1alpha1.GithubIssue{}
+	err := r.Get(ctx, types.NamespacedName{Namespace: marker.Namespace, Name: marker.Name}, owner)
+	if errors.IsNotFound(err) {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	// A resource recreated under the same name is a different owner.
+	return string(owner.UID) != marker.UID, nil
+}
+
+// Resource returns a human readable reference to the owning GithubIssue.
+func (m ownerMarker) Resource() string {
+	resource := m.Namespace + "/" + m.Name
+	if m.ClusterID != "" {
+		resource += " in cluster " + m.ClusterID
+	}
+	return resource
+}
+
+// parseOwnerMarker extracts the owner marker from an issue body. The second
+// return value is false if the body carries no marker.
+func parseOwnerMarker(body string) (ownerMarker, bool) {
+	match := ownerMarkerPattern.FindStringSubmatch(body)
+	if match == nil {
+		return ownerMarker{}, false
+	}
+
+	marker := ownerMarker{}
+	for _, field := range strings.Fields(match[1]) {
+		key, value, ok := strings.Cut(field, "=")
+		if !ok {
+			continue
+		}
+		value, err := url.QueryUnescape(value)
+		if err != nil {
+			continue
+		}
+		switch key {
+		case "uid":
+			marker.UID = value
+		case "resource":
+			marker.Namespace, marker.Name, _ = strings.Cut(value, "/")
+		case "cluster":
+			marker.ClusterID = value
+		}
+	}
+	if marker.UID == "" {
+		return ownerMarker{}, false
+	}
+	return marker, true
+}
+
+// withOwnerMarker returns desc with the owner marker appended, replacing any
+// marker desc already carried.
+func withOwnerMarker(desc string, m ownerMarker) string {
+	desc = stripOwnerMarker(desc)
+	if desc == "" {
+		return m.String()
+	}
+	return desc + "\n\n" + m.String()
+}
+
+// stripOwnerMarker removes the owner marker from an issue body.
+func stripOwnerMarker(body string) string {
+	return ownerMarkerPattern.ReplaceAllString(body, "")
+}
@@ -0,0 +1,68 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Owner marker", func() {
+	marker := ownerMarker{
+		UID:       "3f0c2a8e-1d7b-4c52-9d0e-6a1f2b3c4d5e",
+		Namespace: "default",
+		Name:      "my-issue",
+		ClusterID: "prod east",
+	}
+
+	It("should round-trip through an issue body", func() {
+		body := withOwnerMarker("some description", marker)
+		Expect(body).To(HavePrefix("some description\n\n<!-- issues-operator:"))
+
+		parsed, ok := parseOwnerMarker(body)
+		Expect(ok).To(BeTrue())
+		Expect(parsed).To(Equal(marker))
+		Expect(stripOwnerMarker(body)).To(Equal("some description"))
+	})
+
+	It("should replace an existing marker instead of appending another", func() {
+		other := ownerMarker{UID: "other", Namespace: "default", Name: "other"}
+		body := withOwnerMarker(withOwnerMarker("description", other), marker)
+		Expect(ownerMarkerPattern.FindAllString(body, -1)).To(HaveLen(1))
+
+		parsed, ok := parseOwnerMarker(body)
+		Expect(ok).To(BeTrue())
+		Expect(parsed.UID).To(Equal(marker.UID))
+	})
+
+	It("should report bodies without a marker", func() {
+		_, ok := parseOwnerMarker("filed by hand <!-- just a comment -->")
+		Expect(ok).To(BeFalse())
+	})
+
+	It("should only be owned by the same resource in the same cluster", func() {
+		Expect(marker.Owns(marker)).To(BeTrue())
+
+		otherCluster := marker
+		otherCluster.ClusterID = "prod west"
+		Expect(marker.Owns(otherCluster)).To(BeFalse())
+
+		otherResource := marker
+		otherResource.UID = "other"
+		Expect(marker.Owns(otherResource)).To(BeFalse())
+	})
+})
@@ -0,0 +1,192 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/zszabo-rh/issues-operator/gitclient"
+)
+
+const (
+	// DefaultCredentialsCheckInterval is how often CredentialsMonitor
+	// checks the operator's credentials unless configured otherwise.
+	DefaultCredentialsCheckInterval = 5 * time.Minute
+
+	// DefaultExpiryWarning is how long before a credential expires
+	// CredentialsMonitor starts warning about it unless configured
+	// otherwise.
+	DefaultExpiryWarning = 7 * 24 * time.Hour
+)
+
+// CredentialCheck validates one of the operator's own credentials.
+type CredentialCheck struct {
+	// Host is the GitHub host the credential is used for.
+	Host string
+	// Check asks GitHub about the credential.
+	Check func(ctx context.Context) (gitclient.CredentialInfo, error)
+}
+
+// CredentialsMonitor periodically validates the operator's own credentials
+// against GitHub. It backs the readyz check, so that the operator is not
+// ready while a credential is missing, rejected or expired, and tells the
+// reconciler which hosts it may only read from.
+type CredentialsMonitor struct {
+	Checks []CredentialCheck
+	// Interval between checks. Defaults to DefaultCredentialsCheckInterval.
+	Interval time.Duration
+	// ExpiryWarning is how long before expiry a credential is reported as
+	// expiring. Defaults to DefaultExpiryWarning.
+	ExpiryWarning time.Duration
+
+	mu      sync.RWMutex
+	checked bool
+	results []credentialResult
+}
+
+type credentialResult struct {
+	host string
+	info gitclient.CredentialInfo
+	err  error
+}
+
+// Start checks the credentials every Interval until ctx is done.
+func (m *CredentialsMonitor) Start(ctx context.Context) error {
+	interval := m.Interval
+	if interval <= 0 {
+		interval = DefaultCredentialsCheckInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		m.CheckNow(ctx)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// NeedLeaderElection is false, as every replica reports its own readiness.
+func (m *CredentialsMonitor) NeedLeaderElection() bool {
+	return false
+}
+
+// CheckNow checks every credential once.
+func (m *CredentialsMonitor) CheckNow(ctx context.Context) {
+	log := log.FromContext(ctx).WithName("credentials")
+
+	results := make([]credentialResult, 0, len(m.Checks))
+	for _, check := range m.Checks {
+		info, err := check.Check(ctx)
+		result := credentialResult{host: check.Host, info: info, err: err}
+		results = append(results, result)
+
+		switch {
+		case err != nil:
+			log.Error(err, "GitHub credential check failed", "host", check.Host, "token", info.Fingerprint)
+		case m.expiring(info):
+			log.Info("GitHub credential is about to expire", "host", check.Host, "login", info.Login, "expiresAt", info.ExpiresAt)
+		case info.ReadOnly:
+			log.Info("GitHub credential lacks write access, issues will not be changed", "host", check.Host, "login", info.Login, "scopes", info.Scopes)
+		}
+		recordCredentialMetrics(result)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.checked = true
+	m.results = results
+}
+
+// Checker is a healthz.Checker failing until every credential passed its
+// last check.
+func (m *CredentialsMonitor) Checker(_ *http.Request) error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if !m.checked {
+		return errors.New("GitHub credentials have not been checked yet")
+	}
+	var errs []error
+	for _, result := range m.results {
+		switch {
+		case result.err != nil:
+			errs = append(errs, fmt.Errorf("credential for %s: %w", result.host, result.err))
+		case !result.info.ExpiresAt.IsZero() && time.Now().After(result.info.ExpiresAt):
+			errs = append(errs, fmt.Errorf("credential for %s expired at %s", result.host, result.info.ExpiresAt.Format(time.RFC3339)))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ReadOnly returns why the operator's credentials for host cannot write
+// issues, or "" if they can. Hosts are only read-only if every credential for
+// them is.
+func (m *CredentialsMonitor) ReadOnly(host string) string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var logins []string
+	for _, result := range m.results {
+		if result.host != host || result.err != nil {
+			continue
+		}
+		if !result.info.ReadOnly {
+			return ""
+		}
+		logins = append(logins, result.info.Login)
+	}
+	if len(logins) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("the credentials for %s (%s) lack write access to issues", host, strings.Join(logins, ", "))
+}
+
+// Warnings returns a message for every credential for host that expires
+// within ExpiryWarning.
+func (m *CredentialsMonitor) Warnings(host string) []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var warnings []string
+	for _, result := range m.results {
+		if result.host == host && result.err == nil && m.expiring(result.info) {
+			warnings = append(warnings, fmt.Sprintf("the credential of %s for %s expires at %s",
+				result.info.Login, host, result.info.ExpiresAt.Format(time.RFC3339)))
+		}
+	}
+	return warnings
+}
+
+func (m *CredentialsMonitor) expiring(info gitclient.CredentialInfo) bool {
+	warning := m.ExpiryWarning
+	if warning <= 0 {
+		warning = DefaultExpiryWarning
+	}
+	return !info.ExpiresAt.IsZero() && time.Until(info.ExpiresAt) < warning
+}
@@ -0,0 +1,119 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/zszabo-rh/issues-operator/gitclient"
+)
+
+var _ = Describe("Credentials monitor", func() {
+	ctx := context.Background()
+
+	check := func(info gitclient.CredentialInfo, err error) CredentialCheck {
+		return CredentialCheck{
+			Host: "github.com",
+			Check: func(context.Context) (gitclient.CredentialInfo, error) {
+				return info, err
+			},
+		}
+	}
+
+	It("should not be ready before the first check", func() {
+		monitor := &CredentialsMonitor{Checks: []CredentialCheck{check(gitclient.CredentialInfo{Login: "octocat"}, nil)}}
+		Expect(monitor.Checker(nil)).To(MatchError(ContainSubstring("not been checked")))
+
+		monitor.CheckNow(ctx)
+		Expect(monitor.Checker(nil)).To(Succeed())
+	})
+
+	It("should not be ready while a credential is rejected or expired", func() {
+		monitor := &CredentialsMonitor{Checks: []CredentialCheck{
+			check(gitclient.CredentialInfo{}, errors.New("401 Unauthorized: Bad credentials")),
+		}}
+		monitor.CheckNow(ctx)
+		Expect(monitor.Checker(nil)).To(MatchError(ContainSubstring("Bad credentials")))
+
+		monitor = &CredentialsMonitor{Checks: []CredentialCheck{
+			check(gitclient.CredentialInfo{Login: "octocat", ExpiresAt: time.Now().Add(-time.Hour)}, nil),
+		}}
+		monitor.CheckNow(ctx)
+		Expect(monitor.Checker(nil)).To(MatchError(ContainSubstring("expired")))
+	})
+
+	It("should warn about credentials close to expiry", func() {
+		monitor := &CredentialsMonitor{
+			Checks:        []CredentialCheck{check(gitclient.CredentialInfo{Login: "octocat", ExpiresAt: time.Now().Add(time.Hour)}, nil)},
+			ExpiryWarning: 24 * time.Hour,
+		}
+		monitor.CheckNow(ctx)
+		Expect(monitor.Checker(nil)).To(Succeed())
+		Expect(monitor.Warnings("github.com")).To(ConsistOf(ContainSubstring("octocat")))
+		Expect(monitor.Warnings("github.example.com")).To(BeEmpty())
+	})
+
+	It("should only be read-only when no credential for the host can write", func() {
+		monitor := &CredentialsMonitor{Checks: []CredentialCheck{
+			check(gitclient.CredentialInfo{Login: "reader", ReadOnly: true}, nil),
+		}}
+		monitor.CheckNow(ctx)
+		Expect(monitor.ReadOnly("github.com")).To(ContainSubstring("reader"))
+
+		monitor.Checks = append(monitor.Checks, check(gitclient.CredentialInfo{Login: "writer"}, nil))
+		monitor.CheckNow(ctx)
+		Expect(monitor.ReadOnly("github.com")).To(BeEmpty())
+	})
+
+	It("should report a rotated token in place of the one before it", func() {
+		expiresAt := time.Now().Add(time.Hour).Truncate(time.Second)
+		info := gitclient.CredentialInfo{Login: "octocat", Fingerprint: "sha256:old", Credential: "file:/token", ExpiresAt: expiresAt}
+		monitor := &CredentialsMonitor{Checks: []CredentialCheck{{
+			Host: "rotation.example.com",
+			Check: func(context.Context) (gitclient.CredentialInfo, error) {
+				return info, nil
+			},
+		}}}
+		monitor.CheckNow(ctx)
+
+		info.Fingerprint = "sha256:new"
+		info.ExpiresAt = expiresAt.Add(24 * time.Hour)
+		monitor.CheckNow(ctx)
+
+		registry := prometheus.NewRegistry()
+		Expect(registry.Register(credentialExpiry)).To(Succeed())
+		families, err := registry.Gather()
+		Expect(err).NotTo(HaveOccurred())
+		var expiries []float64
+		for _, family := range families {
+			for _, metric := range family.GetMetric() {
+				for _, label := range metric.GetLabel() {
+					if label.GetName() == "host" && label.GetValue() == "rotation.example.com" {
+						expiries = append(expiries, metric.GetGauge().GetValue())
+					}
+				}
+			}
+		}
+		Expect(expiries).To(Equal([]float64{float64(info.ExpiresAt.Unix())}))
+	})
+})
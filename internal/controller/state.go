@@ -0,0 +1,71 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	trainingv1alpha1 "github.com/zszabo-rh/issues-operator/api/v1alpha1"
+	"github.com/zszabo-rh/issues-operator/gitclient"
+)
+
+// desiredEdit returns the changes that bring existing in line with the spec
+// of res, with want holding the desired title, body, assignees and
+// milestone. It is empty if the issue is up to date.
+func desiredEdit(res *trainingv1alpha1.GithubIssue, existing gitclient.GitIssue, want gitclient.GitIssue) gitclient.IssueEdit {
+	var edit gitclient.IssueEdit
+	if existing.Title != want.Title || existing.Description != want.Description {
+		edit.Title = want.Title
+		edit.Description = want.Description
+	}
+	edit.State, edit.StateReason = desiredState(res, existing)
+	if labels := desiredLabels(res, existing); labels != nil {
+		edit.Labels = &labels
+	}
+	if assignees := desiredAssignees(res, existing, want); assignees != nil {
+		edit.Assignees = &assignees
+	}
+	if want.Milestone != nil && (existing.Milestone == nil || existing.Milestone.Number != want.Milestone.Number) {
+		edit.Milestone = want.Milestone.Number
+	}
+	return edit
+}
+
+// desiredState returns the state and state reason to move existing to, or
+// empty strings if its state should be left alone. Under the Respect policy
+// spec.state is only applied to a spec that has not been synced yet, so that
+// changes made on GitHub stick until the spec is edited.
+func desiredState(res *trainingv1alpha1.GithubIssue, existing gitclient.GitIssue) (string, string) {
+	state := res.Spec.State
+	if state == "" {
+		return "", ""
+	}
+
+	reason := ""
+	if state == gitclient.StateClosed {
+		reason = res.Spec.StateReason
+		if reason == "" {
+			reason = gitclient.StateReasonCompleted
+		}
+	}
+	if existing.Status == state && (state == gitclient.StateOpen || existing.StateReason == reason) {
+		return "", ""
+	}
+
+	if res.Spec.StatePolicy == trainingv1alpha1.StatePolicyRespect && res.Status.ObservedGeneration == res.Generation {
+		return "", ""
+	}
+	return state, reason
+}
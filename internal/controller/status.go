@@ -0,0 +1,94 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"strconv"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	trainingv1alpha1 "github.com/zszabo-rh/issues-operator/api/v1alpha1"
+	"github.com/zszabo-rh/issues-operator/gitclient"
+)
+
+// recordIssue copies what GitHub reports about issue to the status of res.
+func recordIssue(res *trainingv1alpha1.GithubIssue, issue gitclient.GitIssue) {
+	status := &res.Status
+	status.IssueNumber = issue.Id
+	status.State = issue.Status
+	status.StateReason = issue.StateReason
+	status.HTMLURL = issue.HTMLURL
+	status.Author = login(issue.User)
+	status.CreatedAt = parseTime(issue.CreatedAt)
+	status.LastUpdated = parseTime(issue.LastUpdated)
+	status.ClosedAt = parseTime(issue.ClosedAt)
+	status.ClosedBy = login(issue.ClosedBy)
+	status.Comments = issue.Comments
+	status.Labels = nil
+	if len(issue.Labels) > 0 {
+		status.Labels = labelNames(issue.Labels)
+	}
+	status.Milestone = nil
+	if issue.Milestone != nil {
+		status.Milestone = &trainingv1alpha1.MilestoneStatus{Number: issue.Milestone.Number, Title: issue.Milestone.Title}
+	}
+}
+
+// boundIssue returns the number of the issue res is bound to, or 0.
+func boundIssue(res *trainingv1alpha1.GithubIssue) int {
+	if res.Spec.IssueNumber != 0 {
+		return res.Spec.IssueNumber
+	}
+	return res.Status.IssueNumber
+}
+
+// issueURL returns the web address of issue number in the repository of res,
+// or of the repository itself if number is 0. Events carry it so that users
+// can get to the issue without reading the status.
+func issueURL(res *trainingv1alpha1.GithubIssue, number int) string {
+	ref, err := gitclient.ParseRepo(res.Spec.Repository)
+	if err != nil {
+		return res.Spec.Repository
+	}
+	url := "https://" + ref.Host + "/" + ref.FullName()
+	if number != 0 {
+		url += "/issues/" + strconv.Itoa(number)
+	}
+	return url
+}
+
+// parseTime parses a timestamp from the GitHub API, returning nil for
+// missing or malformed ones.
+func parseTime(value string) *metav1.Time {
+	if value == "" {
+		return nil
+	}
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil
+	}
+	t := metav1.NewTime(parsed)
+	return &t
+}
+
+func login(user *gitclient.GitUser) string {
+	if user == nil {
+		return ""
+	}
+	return user.Login
+}